@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+
+	"go-query/helpers"
+	"go-query/process_identifiers"
+
+	"go.uber.org/zap"
+)
+
+func runCommunities(args []string) error {
+	flags := flag.NewFlagSet("communities", flag.ExitOnError)
+	graphPath := flags.String("in", defaultGraphPath, "graph written by the graph command")
+	communitiesPath := flags.String("out", defaultCommunitiesPath, "where to write the communities")
+	flags.Parse(args)
+
+	logger := helpers.InitializeLogger()
+	defer logger.Sync()
+
+	guacIdGraph, err := processidentifiers.LoadGuacIDGraph(*graphPath)
+	if err != nil {
+		return err
+	}
+
+	communities := processidentifiers.RecursiveCommunityDetection(guacIdGraph)
+
+	if err := processidentifiers.SaveCommunities(*communitiesPath, communities); err != nil {
+		return err
+	}
+
+	logger.Info("detected communities", zap.Int("communities", len(communities)), zap.String("out", *communitiesPath))
+	return nil
+}
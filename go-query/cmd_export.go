@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go-query/process_identifiers"
+	"go-query/schemas"
+)
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	guacIDsPath := flags.String("in", defaultGuacIDsPath, "GuacIDs written by extract")
+	communitiesPath := flags.String("communities", "", "communities written by the communities command, to label each GuacID with")
+	format := flags.String("format", "csv", "export format: csv or jsonl")
+	out := flags.String("out", "", "where to write the export; stdout when empty")
+	flags.Parse(args)
+
+	GuacIDs, err := processidentifiers.LoadGuacIDs(*guacIDsPath)
+	if err != nil {
+		return err
+	}
+
+	var communities []schemas.Community
+	if *communitiesPath != "" {
+		if communities, err = processidentifiers.LoadCommunities(*communitiesPath); err != nil {
+			return err
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("unable to create export file %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return processidentifiers.ExportGuacIDs(w, *format, processidentifiers.GuacIDList(GuacIDs), communities)
+}
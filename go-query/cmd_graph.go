@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-query/helpers"
+	"go-query/process_identifiers"
+
+	"github.com/dominikbraun/graph/draw"
+	"go.uber.org/zap"
+)
+
+func runGraph(args []string) error {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	guacIDsPath := flags.String("in", defaultGuacIDsPath, "GuacIDs written by extract")
+	linksPath := flags.String("links", defaultLinksPath, "GuacID links written by extract; empty to leave them out")
+	graphPath := flags.String("out", defaultGraphPath, "where to write the graph")
+	dotPath := flags.String("dot", "", "also draw the graph in DOT format to this file")
+	similarityScore := flags.Float64("name-similarity", 0, "also join names at least this similar, from 0 to 1, with weighted edges; 0 to leave them out")
+	linkKinds := flags.String("link-kinds", strings.Join(processidentifiers.DefaultGraphLinkKinds, ","), "comma separated kinds of the -links to join with edges; slsa_built_from is provenance, not identity")
+	lsh := flags.Bool("lsh", false, "find the names to compare for -name-similarity with MinHash LSH rather than by shared tokens")
+	flags.Parse(args)
+
+	logger := helpers.InitializeLogger()
+	defer logger.Sync()
+
+	GuacIDs, err := processidentifiers.LoadGuacIDs(*guacIDsPath)
+	if err != nil {
+		return err
+	}
+
+	guacIdGraph, err := processidentifiers.CreateGuacIDGraph(logger, processidentifiers.GuacIDList(GuacIDs))
+	if err != nil {
+		return fmt.Errorf("unable to create GuacID graph %v", err)
+	}
+
+	if *linksPath != "" {
+		links, err := processidentifiers.LoadGuacIDLinks(*linksPath)
+		if err != nil {
+			return err
+		}
+		processidentifiers.AddGuacIDLinkEdges(logger, guacIdGraph, GuacIDs, links, strings.Split(*linkKinds, ","))
+	}
+
+	if *similarityScore > 0 {
+		opts := processidentifiers.DefaultNameSimilarityOptions()
+		opts.MinScore = *similarityScore
+		if *lsh {
+			nearDuplicateOpts := processidentifiers.DefaultNearDuplicateOptions()
+			opts.Candidates = func(names []string) ([][2]string, error) {
+				return processidentifiers.LSHNameCandidates(names, nearDuplicateOpts)
+			}
+		}
+		added, err := processidentifiers.AddNameSimilarityEdges(logger, guacIdGraph, processidentifiers.GuacIDList(GuacIDs), opts)
+		if err != nil {
+			return err
+		}
+		logger.Info("added name similarity edges", zap.Int("edges", added))
+	}
+
+	if err := processidentifiers.SaveGuacIDGraph(*graphPath, guacIdGraph); err != nil {
+		return err
+	}
+
+	if *dotPath != "" {
+		file, err := os.Create(*dotPath)
+		if err != nil {
+			return fmt.Errorf("unable to create dot file %v", err)
+		}
+		defer file.Close()
+		if err := draw.DOT(guacIdGraph, file); err != nil {
+			return fmt.Errorf("unable to draw graph %v", err)
+		}
+	}
+
+	order, _ := guacIdGraph.Order()
+	size, _ := guacIdGraph.Size()
+	logger.Info("built GuacID graph", zap.Int("vertices", order), zap.Int("edges", size), zap.String("out", *graphPath))
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"go-query/helpers"
+	"go-query/matching"
+	"go-query/process_identifiers"
+
+	"go.uber.org/zap"
+)
+
+func runLearnAliases(args []string) error {
+	opts := matching.DefaultAliasOptions()
+
+	flags := flag.NewFlagSet("learn-aliases", flag.ExitOnError)
+	guacIDsPath := flags.String("in", defaultGuacIDsPath, "GuacIDs written by extract")
+	linksPath := flags.String("links", defaultLinksPath, "GuacID links written by extract")
+	aliasesPath := flags.String("out", defaultAliasesPath, "where to write the proposed aliases, as a YAML or JSON dictionary extract -aliases reads after review")
+	candidatesPath := flags.String("candidates-out", defaultAliasCandidatesPath, "where to write every candidate with its support, consistency and examples; empty to skip")
+	flags.Int64Var(&opts.MinSupport, "min-support", opts.MinSupport, "leave out pairs seen fewer times than this")
+	flags.Float64Var(&opts.MinConsistency, "min-consistency", opts.MinConsistency, "leave out pairs making up less than this share of the pairings of their vendor or product")
+	flags.Parse(args)
+
+	logger := helpers.InitializeLogger()
+	defer logger.Sync()
+
+	GuacIDs, err := processidentifiers.LoadGuacIDs(*guacIDsPath)
+	if err != nil {
+		return err
+	}
+	links, err := processidentifiers.LoadGuacIDLinks(*linksPath)
+	if err != nil {
+		return err
+	}
+
+	candidates := matching.LearnAliases(GuacIDs, links, opts)
+
+	if *candidatesPath != "" {
+		data, err := json.MarshalIndent(candidates, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to marshal alias candidates %s", err)
+		}
+		if err := os.WriteFile(*candidatesPath, data, 0644); err != nil {
+			return fmt.Errorf("unable to write alias candidates %s", err)
+		}
+	}
+
+	aliases, err := matching.AliasDictionaryFromCandidates(candidates)
+	if err != nil {
+		return fmt.Errorf("cannot build alias dictionary %v", err)
+	}
+	if err := processidentifiers.SaveAliasDictionary(*aliasesPath, aliases); err != nil {
+		return err
+	}
+
+	logger.Info("learned aliases", zap.Int("candidates", len(candidates)), zap.Int("entities", len(aliases.Entities)), zap.String("out", *aliasesPath))
+	return nil
+}
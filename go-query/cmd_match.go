@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+
+	"go-query/helpers"
+	"go-query/matching"
+	"go-query/process_identifiers"
+	"go-query/schemas"
+
+	"go.uber.org/zap"
+)
+
+func runMatch(args []string) error {
+	opts := matching.DefaultOptions()
+
+	flags := flag.NewFlagSet("match", flag.ExitOnError)
+	guacIDsPath := flags.String("in", defaultGuacIDsPath, "GuacIDs written by extract")
+	matchesPath := flags.String("out", defaultMatchesPath, "where to write the matches, as GuacID links the graph command can read")
+	flags.Float64Var(&opts.MinScore, "min-score", opts.MinScore, "leave out matches scoring less than this")
+	fuzzy := flags.Bool("fuzzy", false, "also score names that differ by more than separators and packaging prefixes by their similarity")
+	flags.Parse(args)
+
+	if *fuzzy {
+		opts.NameScore = matching.FuzzyNameScore
+		opts.TokenBlocking = true
+	}
+
+	logger := helpers.InitializeLogger()
+	defer logger.Sync()
+
+	GuacIDs, err := processidentifiers.LoadGuacIDs(*guacIDsPath)
+	if err != nil {
+		return err
+	}
+
+	matches := matching.MatchCPEsToPurls(GuacIDs, opts)
+
+	links := make([]schemas.GuacIDLink, 0, len(matches))
+	for _, match := range matches {
+		links = append(links, match.Link())
+	}
+	if err := processidentifiers.SaveGuacIDLinks(*matchesPath, links); err != nil {
+		return err
+	}
+
+	logger.Info("matched CPEs to purls", zap.Int("matches", len(matches)), zap.String("out", *matchesPath))
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+
+	"go-query/helpers"
+	"go-query/process_identifiers"
+	"go-query/schemas"
+
+	"go.uber.org/zap"
+)
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	guacIDsPath := flags.String("in", defaultGuacIDsPath, "GuacIDs to migrate in place")
+	linksPath := flags.String("links", defaultLinksPath, "GuacID links to migrate in place; empty to skip")
+	versionLinksPath := flags.String("version-links", defaultVersionLinksPath, "version links to migrate in place; empty to skip")
+	mapPath := flags.String("map-out", defaultDigestMapPath, "where to write the map from old digests to new ones")
+	flags.Parse(args)
+
+	logger := helpers.InitializeLogger()
+	defer logger.Sync()
+
+	GuacIDs, err := processidentifiers.LoadGuacIDs(*guacIDsPath)
+	if err != nil {
+		return err
+	}
+
+	var links []schemas.GuacIDLink
+	if *linksPath != "" {
+		if links, err = processidentifiers.LoadGuacIDLinks(*linksPath); err != nil {
+			return err
+		}
+	}
+	var versionLinks []schemas.GuacIDLink
+	if *versionLinksPath != "" {
+		if versionLinks, err = processidentifiers.LoadGuacIDLinks(*versionLinksPath); err != nil {
+			return err
+		}
+	}
+
+	migrated, links, renamed := processidentifiers.MigrateGuacIDs(GuacIDs, links)
+	versionLinks = processidentifiers.RenameGuacIDLinks(versionLinks, renamed)
+
+	if err := processidentifiers.SaveDigestMap(*mapPath, renamed); err != nil {
+		return err
+	}
+	if err := processidentifiers.SaveGuacIDs(*guacIDsPath, migrated); err != nil {
+		return err
+	}
+	if *linksPath != "" {
+		if err := processidentifiers.SaveGuacIDLinks(*linksPath, links); err != nil {
+			return err
+		}
+	}
+	if *versionLinksPath != "" {
+		if err := processidentifiers.SaveGuacIDLinks(*versionLinksPath, versionLinks); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("migrated GuacID digests", zap.Int("guacIDs", len(migrated)), zap.Int("renamed", len(renamed)), zap.String("map", *mapPath))
+	return nil
+}
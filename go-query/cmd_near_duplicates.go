@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+
+	"go-query/helpers"
+	"go-query/process_identifiers"
+
+	"go.uber.org/zap"
+)
+
+func runNearDuplicates(args []string) error {
+	opts := processidentifiers.DefaultNearDuplicateOptions()
+
+	flags := flag.NewFlagSet("near-duplicates", flag.ExitOnError)
+	guacIDsPath := flags.String("in", defaultGuacIDsPath, "GuacIDs written by extract")
+	nearDuplicatesPath := flags.String("out", defaultNearDuplicatesPath, "where to write the near duplicates, as GuacID links the graph command can read")
+	flags.IntVar(&opts.Bands, "bands", opts.Bands, "LSH bands; more bands find less alike GuacIDs")
+	flags.IntVar(&opts.Rows, "rows", opts.Rows, "signature rows per LSH band; more rows find only more alike GuacIDs")
+	flags.Float64Var(&opts.MinJaccard, "min-jaccard", opts.MinJaccard, "leave out pairs estimated less alike than this")
+	flags.IntVar(&opts.MaxBucket, "max-bucket", opts.MaxBucket, "skip LSH buckets of more GuacIDs than this; 0 to keep them all")
+	flags.Parse(args)
+
+	logger := helpers.InitializeLogger()
+	defer logger.Sync()
+
+	GuacIDs, err := processidentifiers.LoadGuacIDs(*guacIDsPath)
+	if err != nil {
+		return err
+	}
+
+	links, err := processidentifiers.NearDuplicateLinks(GuacIDs, opts)
+	if err != nil {
+		return err
+	}
+	if err := processidentifiers.SaveGuacIDLinks(*nearDuplicatesPath, links); err != nil {
+		return err
+	}
+
+	logger.Info("found near-duplicate GuacIDs", zap.Int("pairs", len(links)), zap.String("out", *nearDuplicatesPath))
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"go-query/process_identifiers"
+	"go-query/schemas"
+)
+
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	guacIDsPath := flags.String("in", defaultGuacIDsPath, "GuacIDs written by extract")
+	linksPath := flags.String("links", defaultLinksPath, "GuacID links written by extract; empty to leave them out")
+	communitiesPath := flags.String("communities", "", "communities written by the communities command")
+	top := flags.Int("top", 10, "how many of the most seen GuacIDs to list")
+	asJSON := flags.Bool("json", false, "print the summary as JSON")
+	flags.Parse(args)
+
+	GuacIDs, err := processidentifiers.LoadGuacIDs(*guacIDsPath)
+	if err != nil {
+		return err
+	}
+
+	var links []schemas.GuacIDLink
+	if *linksPath != "" {
+		if links, err = processidentifiers.LoadGuacIDLinks(*linksPath); err != nil {
+			return err
+		}
+	}
+
+	var communities []schemas.Community
+	if *communitiesPath != "" {
+		if communities, err = processidentifiers.LoadCommunities(*communitiesPath); err != nil {
+			return err
+		}
+	}
+
+	stats := processidentifiers.SummarizeGuacIDs(GuacIDs, links, communities, *top)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	fmt.Printf("GuacIDs:      %d (seen %d times)\n", stats.GuacIDs, stats.Occurrences)
+	printCounts("by kind", stats.ByKind)
+	printCounts("by ecosystem", stats.ByEcosystem)
+	printCounts("by provenance", stats.ByProvenance)
+	if len(stats.AliasesFired) > 0 {
+		printCounts("aliases fired", stats.AliasesFired)
+	}
+	fmt.Printf("links:        %d\n", stats.Links)
+	printCounts("links by kind", stats.LinksByKind)
+	if *communitiesPath != "" {
+		fmt.Printf("communities:  %d (largest %d)\n", stats.Communities, stats.LargestCommunity)
+	}
+	fmt.Println("most seen:")
+	for _, guacID := range stats.MostSeen {
+		fmt.Printf("  %6d  %-8s %s %s %s %s\n", guacID.Count, guacID.Kind, guacID.Ecosystem, guacID.Namespace, guacID.Name, guacID.Version)
+	}
+	return nil
+}
+
+func printCounts(title string, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Printf("%s:\n", title)
+	for _, key := range keys {
+		fmt.Printf("  %6d  %s\n", counts[key], key)
+	}
+}
@@ -0,0 +1,205 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// IdentifierKind is the form of an identifier value, as told by
+// ClassifyIdentifier.
+type IdentifierKind string
+
+const (
+	// IdentifierText is anything not recognized as one of the forms below,
+	// such as a package name.
+	IdentifierText IdentifierKind = "text"
+	IdentifierUUID IdentifierKind = "uuid"
+	// IdentifierHash is bare hex, the algorithm guessed from its length.
+	IdentifierHash IdentifierKind = "hash"
+	// IdentifierDigest is an algorithm:hex digest, as used by OCI and GUAC.
+	IdentifierDigest IdentifierKind = "digest"
+	// IdentifierSRI is a Subresource Integrity algorithm-base64 hash.
+	IdentifierSRI IdentifierKind = "sri"
+	// IdentifierMultihash is a self-describing multihash, in hex or as a
+	// base58btc CIDv0.
+	IdentifierMultihash IdentifierKind = "multihash"
+	// IdentifierGitoid is a gitoid URI, the form OmniBOR identifies
+	// artifacts by.
+	IdentifierGitoid IdentifierKind = "gitoid"
+	// IdentifierGoSum is a Go module h1: checksum from go.sum.
+	IdentifierGoSum IdentifierKind = "go_sum"
+)
+
+// ClassifiedIdentifier is the result of ClassifyIdentifier. Value is the
+// identifier in a normalized form: algorithm:lowercase-hex for every kind of
+// hash, so that one hash written in different forms compares equal, the
+// lowercase UUID, and the trimmed input for text.
+type ClassifiedIdentifier struct {
+	Kind      IdentifierKind
+	Algorithm string
+	Value     string
+}
+
+// IsHard reports whether the identifier names exactly one thing, the way a
+// content hash or a UUID does, rather than being a name that may be shared.
+func (c ClassifiedIdentifier) IsHard() bool {
+	return c.Kind != IdentifierText
+}
+
+var (
+	uuidPattern   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexPattern    = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	gitoidPattern = regexp.MustCompile(`^(?i)gitoid:(blob|tree|commit|tag):(sha1|sha256):([0-9a-f]+)$`)
+)
+
+// hashAlgorithmSizes is the digest size in bytes of the algorithms recognized
+// in algorithm:hex and SRI form.
+var hashAlgorithmSizes = map[string]int{
+	"md5":      16,
+	"sha1":     20,
+	"sha224":   28,
+	"sha256":   32,
+	"sha384":   48,
+	"sha512":   64,
+	"sha3-224": 28,
+	"sha3-256": 32,
+	"sha3-384": 48,
+	"sha3-512": 64,
+	"blake3":   32,
+}
+
+// bareHashAlgorithms guesses the algorithm of bare hex by its size in bytes,
+// taking the most common algorithm of each size.
+var bareHashAlgorithms = map[int]string{
+	16: "md5",
+	20: "sha1",
+	28: "sha224",
+	32: "sha256",
+	48: "sha384",
+	64: "sha512",
+}
+
+// multihashAlgorithms maps the multicodec codes of the multihash functions
+// to their names.
+var multihashAlgorithms = map[byte]string{
+	0x11: "sha1",
+	0x12: "sha256",
+	0x13: "sha512",
+	0x14: "sha3-512",
+	0x15: "sha3-384",
+	0x16: "sha3-256",
+	0x17: "sha3-224",
+}
+
+// ClassifyIdentifier tells what kind of identifier s is, with its algorithm
+// if it is a hash.
+func ClassifyIdentifier(s string) ClassifiedIdentifier {
+	s = strings.TrimSpace(s)
+	text := ClassifiedIdentifier{Kind: IdentifierText, Value: s}
+	if s == "" {
+		return text
+	}
+
+	if uuidPattern.MatchString(s) {
+		return ClassifiedIdentifier{Kind: IdentifierUUID, Value: strings.ToLower(s)}
+	}
+
+	if match := gitoidPattern.FindStringSubmatch(s); match != nil {
+		algorithm := strings.ToLower(match[2])
+		if len(match[3]) == 2*hashAlgorithmSizes[algorithm] {
+			return ClassifiedIdentifier{Kind: IdentifierGitoid, Algorithm: algorithm, Value: strings.ToLower(s)}
+		}
+		return text
+	}
+
+	if checksum, ok := strings.CutPrefix(s, "h1:"); ok {
+		if sum, err := base64.StdEncoding.DecodeString(checksum); err == nil && len(sum) == 32 {
+			return ClassifiedIdentifier{Kind: IdentifierGoSum, Algorithm: "sha256", Value: "sha256:" + hex.EncodeToString(sum)}
+		}
+		return text
+	}
+
+	if algorithm, digest, ok := strings.Cut(s, ":"); ok {
+		algorithm = normalizeHashAlgorithm(algorithm)
+		if size, known := hashAlgorithmSizes[algorithm]; known && len(digest) == 2*size && hexPattern.MatchString(digest) {
+			return ClassifiedIdentifier{Kind: IdentifierDigest, Algorithm: algorithm, Value: algorithm + ":" + strings.ToLower(digest)}
+		}
+		return text
+	}
+
+	if algorithm, encoded, ok := strings.Cut(s, "-"); ok {
+		if size, known := hashAlgorithmSizes[algorithm]; known {
+			if sum, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(sum) == size {
+				return ClassifiedIdentifier{Kind: IdentifierSRI, Algorithm: algorithm, Value: algorithm + ":" + hex.EncodeToString(sum)}
+			}
+		}
+	}
+
+	if hexPattern.MatchString(s) && len(s)%2 == 0 {
+		raw, _ := hex.DecodeString(s)
+		if classified, ok := classifyMultihash(raw); ok {
+			return classified
+		}
+		if algorithm, ok := bareHashAlgorithms[len(raw)]; ok {
+			return ClassifiedIdentifier{Kind: IdentifierHash, Algorithm: algorithm, Value: algorithm + ":" + strings.ToLower(s)}
+		}
+		return text
+	}
+
+	// a CIDv0 is a base58btc sha256 multihash, and always starts with Qm
+	if len(s) == 46 && strings.HasPrefix(s, "Qm") {
+		if raw, ok := decodeBase58(s); ok {
+			if classified, ok := classifyMultihash(raw); ok {
+				return classified
+			}
+		}
+	}
+
+	return text
+}
+
+// classifyMultihash recognizes <code><length><digest>, requiring the length
+// to match both the digest and the size of the function.
+func classifyMultihash(raw []byte) (ClassifiedIdentifier, bool) {
+	if len(raw) < 3 {
+		return ClassifiedIdentifier{}, false
+	}
+	algorithm, ok := multihashAlgorithms[raw[0]]
+	if !ok || int(raw[1]) != len(raw)-2 || hashAlgorithmSizes[algorithm] != len(raw)-2 {
+		return ClassifiedIdentifier{}, false
+	}
+	return ClassifiedIdentifier{Kind: IdentifierMultihash, Algorithm: algorithm, Value: algorithm + ":" + hex.EncodeToString(raw[2:])}, true
+}
+
+// normalizeHashAlgorithm maps spellings such as SHA-256 and sha_256 onto the
+// names of hashAlgorithmSizes.
+func normalizeHashAlgorithm(algorithm string) string {
+	algorithm = strings.ToLower(algorithm)
+	if strings.HasPrefix(algorithm, "sha3") {
+		return "sha3-" + strings.TrimLeft(algorithm[len("sha3"):], "-_")
+	}
+	return strings.NewReplacer("-", "", "_", "").Replace(algorithm)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func decodeBase58(s string) ([]byte, bool) {
+	value := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range s {
+		digit := strings.IndexRune(base58Alphabet, r)
+		if digit < 0 {
+			return nil, false
+		}
+		value.Mul(value, radix)
+		value.Add(value, big.NewInt(int64(digit)))
+	}
+
+	decoded := value.Bytes()
+	// every leading 1 stands for a leading zero byte
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	return append(make([]byte, zeros), decoded...), true
+}
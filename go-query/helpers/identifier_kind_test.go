@@ -0,0 +1,64 @@
+package helpers
+
+import "testing"
+
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestClassifyIdentifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		kind      IdentifierKind
+		algorithm string
+		hard      bool
+	}{
+		{"empty", "", IdentifierText, "", false},
+		{"package name", "left-pad", IdentifierText, "", false},
+		{"version", "v1.2.3", IdentifierText, "", false},
+		{"uuid", "123E4567-E89B-12D3-A456-426614174000", IdentifierUUID, "", true},
+		{"md5", "d41d8cd98f00b204e9800998ecf8427e", IdentifierHash, "md5", true},
+		{"sha1", "da39a3ee5e6b4b0d3255bfef95601890afd80709", IdentifierHash, "sha1", true},
+		{"sha224", "d14a028c2a3a2bc9476102bb288234c415a2b01f828ea62ac5b3e42f", IdentifierHash, "sha224", true},
+		{"sha256", emptySHA256, IdentifierHash, "sha256", true},
+		// 24 bytes is the size of no common hash; SHA-224 is 28
+		{"48 hex", "d14a028c2a3a2bc9476102bb288234c415a2b01f828ea62a", IdentifierText, "", false},
+		{"odd hex", "d41d8cd98f00b204e9800998ecf8427", IdentifierText, "", false},
+		{"digest", "sha256:" + emptySHA256, IdentifierDigest, "sha256", true},
+		{"digest spelling", "SHA-256:" + emptySHA256, IdentifierDigest, "sha256", true},
+		{"digest of the wrong size", "sha256:d41d8cd98f00b204e9800998ecf8427e", IdentifierText, "", false},
+		{"sri", "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", IdentifierSRI, "sha256", true},
+		{"multihash", "1220" + emptySHA256, IdentifierMultihash, "sha256", true},
+		{"cidv0", "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR", IdentifierMultihash, "sha256", true},
+		{"gitoid", "gitoid:blob:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64", IdentifierGitoid, "sha1", true},
+		{"gitoid of the wrong size", "gitoid:blob:sha256:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64", IdentifierText, "", false},
+		{"go.sum", "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", IdentifierGoSum, "sha256", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyIdentifier(tt.value)
+			if got.Kind != tt.kind || got.Algorithm != tt.algorithm {
+				t.Errorf("ClassifyIdentifier(%q) = %s %s; want %s %s", tt.value, got.Kind, got.Algorithm, tt.kind, tt.algorithm)
+			}
+			if got.IsHard() != tt.hard {
+				t.Errorf("ClassifyIdentifier(%q).IsHard() = %t; want %t", tt.value, got.IsHard(), tt.hard)
+			}
+		})
+	}
+}
+
+// TestClassifyIdentifierValue checks that one hash written in different forms
+// gets the same normalized value.
+func TestClassifyIdentifierValue(t *testing.T) {
+	for _, value := range []string{
+		emptySHA256,
+		"SHA256:" + emptySHA256,
+		"sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+		"1220" + emptySHA256,
+		"h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+	} {
+		if got := ClassifyIdentifier(value).Value; got != "sha256:"+emptySHA256 {
+			t.Errorf("ClassifyIdentifier(%q).Value = %q; want sha256:%s", value, got, emptySHA256)
+		}
+	}
+}
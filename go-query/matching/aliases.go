@@ -0,0 +1,182 @@
+package matching
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"go-query/schemas"
+	"go-query/similarity"
+)
+
+// AliasCandidate proposes that a CPE vendor or product is another spelling of
+// a purl namespace or name, learned from the CPEs and purls SBOMs attach to
+// the same component.
+type AliasCandidate struct {
+	// Field is schemas.AliasVendor, pairing a vendor with a namespace, or
+	// schemas.AliasProduct, pairing a product with a name.
+	Field      string   `json:"field"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Ecosystems []string `json:"ecosystems"`
+	// Support counts the HasMetadata nodes that paired From with To.
+	Support int64 `json:"support"`
+	// Consistency is the share of the pairings of From that went to To.
+	Consistency float64  `json:"consistency"`
+	Examples    []string `json:"examples,omitempty"`
+}
+
+// AliasOptions configure LearnAliases.
+type AliasOptions struct {
+	MinSupport     int64
+	MinConsistency float64
+	// MaxExamples caps the example pairs kept on each candidate.
+	MaxExamples int
+}
+
+// DefaultAliasOptions want a pairing seen at least twice, and more often than
+// every other pairing of the same vendor or product together.
+func DefaultAliasOptions() AliasOptions {
+	return AliasOptions{MinSupport: 2, MinConsistency: 0.5, MaxExamples: 3}
+}
+
+// distroEcosystems are the purl types whose namespace names the distribution
+// rather than the vendor.
+var distroEcosystems = map[string]bool{"deb": true, "rpm": true, "apk": true, "alpm": true}
+
+// aliasPairing accumulates the pairings of one From with one To.
+type aliasPairing struct {
+	candidate AliasCandidate
+	examples  map[string]bool
+}
+
+// LearnAliases mines the metadata_subject links between the purls of a
+// component and the CPEs attached to it for vendor to namespace and product to
+// name pairs. Pairs whose versions differ are left out: the CPE then names
+// another release, and may well be attached to the wrong component. Pairs that
+// already agree need no alias and are not proposed, but do count against the
+// consistency of the others. Candidates are returned by support, then
+// consistency.
+func LearnAliases(GuacIDs map[string]schemas.GuacID, links []schemas.GuacIDLink, opts AliasOptions) []AliasCandidate {
+	pairings := make(map[string]*aliasPairing)
+	totals := make(map[string]int64)
+
+	observe := func(field, from, to string, purl schemas.GuacID, count int64, example string) {
+		if from == "" || to == "" {
+			return
+		}
+		totals[field+"|"+from] += count
+		if similarity.StripSeparators(from) == similarity.StripSeparators(to) {
+			return
+		}
+
+		key := field + "|" + from + "|" + to
+		pairing, exists := pairings[key]
+		if !exists {
+			pairing = &aliasPairing{
+				candidate: AliasCandidate{Field: field, From: from, To: to},
+				examples:  make(map[string]bool),
+			}
+			pairings[key] = pairing
+		}
+		pairing.candidate.Support += count
+		if !slices.Contains(pairing.candidate.Ecosystems, purl.Ecosystem) {
+			pairing.candidate.Ecosystems = append(pairing.candidate.Ecosystems, purl.Ecosystem)
+		}
+		if len(pairing.examples) < opts.MaxExamples && !pairing.examples[example] {
+			pairing.examples[example] = true
+			pairing.candidate.Examples = append(pairing.candidate.Examples, example)
+		}
+	}
+
+	for _, link := range links {
+		if link.Kind != schemas.GuacIDLinkMetadataSubject {
+			continue
+		}
+		cpe, purl := GuacIDs[link.Target], GuacIDs[link.Source]
+		if cpe.Kind != schemas.GuacIDKindCPE || purl.Kind != schemas.GuacIDKindPurl {
+			continue
+		}
+		if score, _ := compareVersions(cpe, purl); score == 0 {
+			continue
+		}
+
+		count := link.Count
+		if count == 0 {
+			count = 1
+		}
+		example := fmt.Sprintf("%s = %s", cpe.CanonicalCPE, purl.CanonicalPurl)
+		if !distroEcosystems[purl.Ecosystem] {
+			observe(schemas.AliasVendor, cpe.Namespace, purl.Namespace, purl, count, example)
+		}
+		observe(schemas.AliasProduct, cpe.Name, purl.Name, purl, count, example)
+	}
+
+	candidates := []AliasCandidate{}
+	for _, pairing := range pairings {
+		candidate := pairing.candidate
+		total := totals[candidate.Field+"|"+candidate.From]
+		candidate.Consistency = math.Round(float64(candidate.Support)/float64(total)*1000) / 1000
+		if candidate.Support < opts.MinSupport || candidate.Consistency < opts.MinConsistency {
+			continue
+		}
+		sort.Strings(candidate.Ecosystems)
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Support != b.Support {
+			return a.Support > b.Support
+		}
+		if a.Consistency != b.Consistency {
+			return a.Consistency > b.Consistency
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return candidates
+}
+
+// AliasDictionaryFromCandidates turns candidates into a dictionary the
+// resolution stage loads, resolving each vendor and product onto the purl
+// namespace or name it was best paired with. The purl side is canonical since
+// it is the more specific of the two. Later candidates for a vendor or
+// product already resolved are dropped.
+func AliasDictionaryFromCandidates(candidates []AliasCandidate) (*schemas.AliasDictionary, error) {
+	resolved := make(map[string]bool)
+	entities := make(map[string]*schemas.AliasEntity)
+	order := []string{}
+
+	for _, candidate := range candidates {
+		if resolved[candidate.Field+"|"+candidate.From] {
+			continue
+		}
+		resolved[candidate.Field+"|"+candidate.From] = true
+
+		entity, exists := entities[candidate.To]
+		if !exists {
+			entity = &schemas.AliasEntity{Canonical: candidate.To}
+			entities[candidate.To] = entity
+			order = append(order, candidate.To)
+		}
+		switch candidate.Field {
+		case schemas.AliasVendor:
+			entity.Vendors = append(entity.Vendors, candidate.From)
+		case schemas.AliasProduct:
+			entity.Products = append(entity.Products, candidate.From)
+		}
+	}
+
+	list := make([]schemas.AliasEntity, 0, len(order))
+	for _, canonical := range order {
+		list = append(list, *entities[canonical])
+	}
+	return schemas.NewAliasDictionary(list)
+}
@@ -0,0 +1,338 @@
+// Package matching finds CPE and purl GuacIDs that name the same software.
+package matching
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go-query/schemas"
+	"go-query/similarity"
+	"go-query/versions"
+)
+
+// FieldMatch is how well one pair of fields agreed, with a human readable
+// reason. Score is between 0 and 1.
+type FieldMatch struct {
+	Field  string  `json:"field"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// Match is a candidate pair of a CPE and a purl GuacID, scored by the
+// weighted agreement of their fields.
+type Match struct {
+	CPE    string       `json:"cpe"`
+	Purl   string       `json:"purl"`
+	Score  float64      `json:"score"`
+	Fields []FieldMatch `json:"fields"`
+}
+
+// Link turns the match into a GuacID link from the CPE to the purl, with the
+// field reasons as evidence.
+func (m Match) Link() schemas.GuacIDLink {
+	link := schemas.GuacIDLink{
+		Source: m.CPE,
+		Target: m.Purl,
+		Kind:   schemas.GuacIDLinkCPEPurlMatch,
+		Count:  1,
+		Score:  m.Score,
+	}
+	for _, field := range m.Fields {
+		link.Evidence = append(link.Evidence, fmt.Sprintf("%s %.2f: %s", field.Field, field.Score, field.Reason))
+	}
+	return link
+}
+
+// Weights say how much each field contributes to the score of a match.
+type Weights struct {
+	Name      float64
+	Vendor    float64
+	Version   float64
+	Ecosystem float64
+}
+
+// Options configure MatchCPEsToPurls.
+type Options struct {
+	Weights Weights
+	// MinScore drops candidates scoring less.
+	MinScore float64
+	// NameScore compares a CPE product with a purl name. The names given to
+	// it are already lowercase.
+	NameScore func(product, name string) (float64, string)
+	// TokenBlocking also makes candidates of names sharing a token, for a
+	// NameScore such as FuzzyNameScore that sees more than the name keys.
+	TokenBlocking bool
+}
+
+// DefaultOptions weigh the name highest, since candidates are only found
+// through it, then the vendor, then the version and the ecosystem hint.
+func DefaultOptions() Options {
+	return Options{
+		Weights:   Weights{Name: 0.5, Vendor: 0.25, Version: 0.15, Ecosystem: 0.1},
+		MinScore:  0.6,
+		NameScore: compareNames,
+	}
+}
+
+// targetSWEcosystems maps the CPE target_sw values NVD uses onto the purl
+// types of the same platform.
+var targetSWEcosystems = map[string]string{
+	"node.js":    "npm",
+	"nodejs":     "npm",
+	"python":     "pypi",
+	"ruby":       "gem",
+	"rails":      "gem",
+	"rust":       "cargo",
+	"go":         "golang",
+	"golang":     "golang",
+	"java":       "maven",
+	"maven":      "maven",
+	"php":        "composer",
+	".net":       "nuget",
+	"asp.net":    "nuget",
+	"perl":       "cpan",
+	"dart":       "pub",
+	"swift":      "swift",
+	"erlang":     "hex",
+	"elixir":     "hex",
+	"haskell":    "hackage",
+	"debian":     "deb",
+	"alpine":     "apk",
+	"red_hat":    "rpm",
+	"fedora":     "rpm",
+	"jenkins":    "maven",
+	"wordpress":  "composer",
+	"cocoapods":  "cocoapods",
+	"conan":      "conan",
+	"android":    "maven",
+	"kubernetes": "golang",
+}
+
+// MatchCPEsToPurls scores every CPE GuacID against the purl GuacIDs whose
+// name could be its product, returning the candidates that score at least
+// opts.MinScore, best first.
+func MatchCPEsToPurls(GuacIDs map[string]schemas.GuacID, opts Options) []Match {
+	if opts.NameScore == nil {
+		opts.NameScore = compareNames
+	}
+
+	// purls are blocked by the keys of their name, so each CPE is only
+	// compared with plausible candidates
+	purlsByKey := make(map[string][]string)
+	for digest, gID := range GuacIDs {
+		if gID.Kind != schemas.GuacIDKindPurl || gID.Name == "" {
+			continue
+		}
+		for _, key := range blockingKeys(gID.Name, opts.TokenBlocking) {
+			purlsByKey[key] = append(purlsByKey[key], digest)
+		}
+	}
+
+	matches := []Match{}
+	for cpeDigest, cpe := range GuacIDs {
+		if cpe.Kind != schemas.GuacIDKindCPE || cpe.Name == "" {
+			continue
+		}
+
+		candidates := make(map[string]bool)
+		for _, key := range blockingKeys(cpe.Name, opts.TokenBlocking) {
+			for _, purlDigest := range purlsByKey[key] {
+				candidates[purlDigest] = true
+			}
+		}
+
+		for purlDigest := range candidates {
+			match, ok := scoreMatch(cpe, GuacIDs[purlDigest], opts)
+			if !ok || match.Score < opts.MinScore {
+				continue
+			}
+			match.CPE, match.Purl = cpeDigest, purlDigest
+			matches = append(matches, match)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].CPE != matches[j].CPE {
+			return matches[i].CPE < matches[j].CPE
+		}
+		return matches[i].Purl < matches[j].Purl
+	})
+	return matches
+}
+
+// scoreMatch compares a CPE with a purl. It returns false when the versions
+// are known to differ: those name different releases, however alike the
+// rest is.
+func scoreMatch(cpe, purl schemas.GuacID, opts Options) (Match, bool) {
+	match := Match{}
+	add := func(field string, weight, score float64, reason string) {
+		match.Score += weight * score
+		match.Fields = append(match.Fields, FieldMatch{Field: field, Score: score, Reason: reason})
+	}
+
+	nameScore, nameReason := opts.NameScore(strings.ToLower(cpe.Name), strings.ToLower(purl.Name))
+	add("name", opts.Weights.Name, nameScore, nameReason)
+
+	vendorScore, vendorReason := compareVendor(cpe.Namespace, purl)
+	add("vendor", opts.Weights.Vendor, vendorScore, vendorReason)
+
+	versionScore, versionReason := compareVersions(cpe, purl)
+	if versionScore == 0 {
+		return Match{}, false
+	}
+	add("version", opts.Weights.Version, versionScore, versionReason)
+
+	ecosystemScore, ecosystemReason := compareEcosystem(cpe.TargetSW, purl.Ecosystem)
+	add("ecosystem", opts.Weights.Ecosystem, ecosystemScore, ecosystemReason)
+
+	match.Score = math.Round(match.Score*1000) / 1000
+	return match, true
+}
+
+// namePrefixes and nameSuffixes are packaging decorations that distributions
+// and ecosystems add around the upstream name.
+var (
+	namePrefixes = []string{"python-", "python3-", "py-", "node-", "ruby-", "rubygem-", "perl-", "php-", "golang-", "lib"}
+	nameSuffixes = []string{"-js", ".js", "-python", "-go", "-java", "-dev", "-devel", "-bin"}
+)
+
+// stripDecorations removes one packaging prefix and one suffix from a
+// lowercase name.
+func stripDecorations(name string) string {
+	for _, prefix := range namePrefixes {
+		if trimmed := strings.TrimPrefix(name, prefix); trimmed != name && trimmed != "" {
+			name = trimmed
+			break
+		}
+	}
+	for _, suffix := range nameSuffixes {
+		if trimmed := strings.TrimSuffix(name, suffix); trimmed != name && trimmed != "" {
+			name = trimmed
+			break
+		}
+	}
+	return name
+}
+
+// nameKeys are the blocking keys of a name: its normalized form, with and
+// without packaging decorations.
+func nameKeys(name string) []string {
+	lower := strings.ToLower(name)
+	keys := []string{similarity.StripSeparators(lower)}
+	if stripped := similarity.StripSeparators(stripDecorations(lower)); stripped != keys[0] {
+		keys = append(keys, stripped)
+	}
+	return keys
+}
+
+// blockingKeys are the name keys, and with tokens also the tokens of the name
+// long enough to tell names apart.
+func blockingKeys(name string, tokens bool) []string {
+	keys := nameKeys(name)
+	if tokens {
+		for _, token := range similarity.Tokens(name) {
+			if len(token) >= 3 {
+				keys = append(keys, "token|"+token)
+			}
+		}
+	}
+	return keys
+}
+
+func compareNames(product, name string) (float64, string) {
+	switch {
+	case product == name:
+		return 1, fmt.Sprintf("product %s equals name", product)
+	case similarity.SameIgnoringSeparators(product, name):
+		return 0.9, fmt.Sprintf("product %s equals name %s ignoring separators", product, name)
+	case similarity.StripSeparators(stripDecorations(product)) == similarity.StripSeparators(stripDecorations(name)):
+		return 0.75, fmt.Sprintf("product %s equals name %s without packaging prefixes and suffixes", product, name)
+	}
+	return 0, fmt.Sprintf("product %s differs from name %s", product, name)
+}
+
+// fuzzyNameCeiling caps the name score of names only alike by their
+// similarity features, below every name compareNames accepts.
+const fuzzyNameCeiling = 0.7
+
+// FuzzyNameScore is a NameScore that scores the names compareNames finds
+// different by their edit distance, shared tokens and shared trigrams.
+func FuzzyNameScore(product, name string) (float64, string) {
+	if score, reason := compareNames(product, name); score > 0 {
+		return score, reason
+	}
+	features := similarity.Compare(product, name)
+	score := math.Round(fuzzyNameCeiling*features.Score(similarity.DefaultWeights())*1000) / 1000
+	return score, fmt.Sprintf("product %s is like name %s: edit %.2f, tokens %.2f, trigrams %.2f", product, name, features.Edit, features.Jaccard, features.NGram)
+}
+
+// compareVendor looks for the CPE vendor in the purl namespace, whose
+// segments may be a Maven group, a GitHub owner or a distro.
+func compareVendor(vendor string, purl schemas.GuacID) (float64, string) {
+	if vendor == "" {
+		return 0.5, "the CPE has no vendor"
+	}
+	// a vendor resolved through an alias is the namespace itself
+	if strings.EqualFold(vendor, purl.Namespace) {
+		return 1, fmt.Sprintf("vendor %s equals the namespace", vendor)
+	}
+	normalizedVendor := similarity.StripSeparators(vendor)
+
+	segments := strings.FieldsFunc(strings.ToLower(purl.Namespace), func(r rune) bool { return r == '/' || r == '.' || r == '@' })
+	for _, segment := range segments {
+		if similarity.StripSeparators(segment) == normalizedVendor {
+			return 1, fmt.Sprintf("vendor %s is part of namespace %s", vendor, purl.Namespace)
+		}
+	}
+	// projects without an organization are often their own vendor
+	if similarity.StripSeparators(purl.Name) == normalizedVendor {
+		return 0.7, fmt.Sprintf("vendor %s equals the name", vendor)
+	}
+	if purl.Namespace == "" {
+		return 0.4, fmt.Sprintf("the purl has no namespace to compare vendor %s with", vendor)
+	}
+	return 0, fmt.Sprintf("vendor %s is not in namespace %s", vendor, purl.Namespace)
+}
+
+// compareVersions compares the versions by the rules of the purl ecosystem,
+// also trying the CPE version with its update appended, as in 1.0 rc1 for
+// 1.0-rc1. A score of 0 means the versions differ.
+func compareVersions(cpe, purl schemas.GuacID) (float64, string) {
+	purlVersion := versions.GuacIDVersion(purl)
+	if cpe.Version == "" || purl.Version == "" {
+		return 0.5, "one side has no version"
+	}
+
+	cpeVersions := []string{cpe.Version}
+	if cpe.PkgRel != "" {
+		cpeVersions = append(cpeVersions, cpe.Version+"-"+cpe.PkgRel, cpe.Version+cpe.PkgRel, cpe.Version+"."+cpe.PkgRel)
+	}
+	for _, cpeVersion := range cpeVersions {
+		if strings.EqualFold(cpeVersion, purl.Version) || strings.EqualFold(cpeVersion, purlVersion) {
+			return 1, fmt.Sprintf("version %s equals %s", cpeVersion, purl.Version)
+		}
+		if c, err := versions.Compare(purl.Ecosystem, cpeVersion, purl.Version); err == nil && c == 0 {
+			return 0.9, fmt.Sprintf("version %s is equivalent to %s in %s", cpeVersion, purl.Version, purl.Ecosystem)
+		}
+	}
+	return 0, fmt.Sprintf("version %s differs from %s", cpe.Version, purl.Version)
+}
+
+func compareEcosystem(targetSW, ecosystem string) (float64, string) {
+	if targetSW == "" {
+		return 0.5, "the CPE has no target_sw"
+	}
+	hinted, known := targetSWEcosystems[strings.ToLower(targetSW)]
+	switch {
+	case !known:
+		return 0.5, fmt.Sprintf("target_sw %s does not hint at an ecosystem", targetSW)
+	case hinted == ecosystem:
+		return 1, fmt.Sprintf("target_sw %s is ecosystem %s", targetSW, ecosystem)
+	}
+	return 0, fmt.Sprintf("target_sw %s is ecosystem %s, not %s", targetSW, hinted, ecosystem)
+}
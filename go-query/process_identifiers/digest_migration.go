@@ -0,0 +1,74 @@
+package processidentifiers
+
+import (
+	"slices"
+
+	"go-query/schemas"
+)
+
+// MigrateGuacIDs rekeys GuacIDs saved with an older digest version by their
+// current digest and rewrites the links between them to match. It returns the
+// migrated GuacIDs and links and a map from every digest that changed to its
+// replacement. GuacIDs that are already current are kept as they are, so
+// migrating twice changes nothing.
+func MigrateGuacIDs(GuacIDs map[string]schemas.GuacID, links []schemas.GuacIDLink) (map[string]schemas.GuacID, []schemas.GuacIDLink, map[string]string) {
+	migrated := make(map[string]schemas.GuacID, len(GuacIDs))
+	renamed := make(map[string]string)
+
+	// sorted, so that merged GuacIDs keep their provenance in a stable order
+	for _, guacID := range GuacIDList(GuacIDs) {
+		oldDigest := guacID.Digest
+		if guacID.DigestVersion != schemas.GuacIDDigestVersion {
+			guacID.Digest = getGuacIdDigest(guacID)
+			guacID.DigestVersion = schemas.GuacIDDigestVersion
+			if guacID.Digest != oldDigest {
+				renamed[oldDigest] = guacID.Digest
+			}
+		}
+
+		existing, exists := migrated[guacID.Digest]
+		if !exists {
+			migrated[guacID.Digest] = guacID
+			continue
+		}
+		migrated[guacID.Digest] = mergeGuacIDs(existing, guacID)
+	}
+
+	return migrated, RenameGuacIDLinks(links, renamed), renamed
+}
+
+// RenameGuacIDLinks rewrites the digests of links by the map MigrateGuacIDs
+// returns, merging links that end up between the same GuacIDs and dropping
+// those that end up joining a GuacID to itself.
+func RenameGuacIDLinks(links []schemas.GuacIDLink, renamed map[string]string) []schemas.GuacIDLink {
+	renamedLinks := newGuacIDLinks()
+	for _, link := range links {
+		if digest, ok := renamed[link.Source]; ok {
+			link.Source = digest
+		}
+		if digest, ok := renamed[link.Target]; ok {
+			link.Target = digest
+		}
+		if link.Source == link.Target {
+			continue
+		}
+		renamedLinks.merge(link)
+	}
+	return renamedLinks.list()
+}
+
+// mergeGuacIDs folds b into a, counting every document once.
+func mergeGuacIDs(a, b schemas.GuacID) schemas.GuacID {
+	for _, provenance := range b.Provenance {
+		if !slices.Contains(a.Provenance, provenance) {
+			a.Provenance = append(a.Provenance, provenance)
+		}
+	}
+	a.Count = max(int64(len(provenanceDocuments(a.Provenance))), 1)
+	for _, original := range b.Originals {
+		if !slices.Contains(a.Originals, original) {
+			a.Originals = append(a.Originals, original)
+		}
+	}
+	return a
+}
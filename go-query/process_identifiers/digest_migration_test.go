@@ -0,0 +1,96 @@
+package processidentifiers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+	"go.uber.org/zap"
+
+	"go-query/schemas"
+)
+
+// baselineGuacIDs is a GuacIDs.json as the first releases wrote it, without
+// digests, kinds or digest versions, for the purls and CPEs of
+// TestLoadBaselineGuacIDs.
+const baselineGuacIDs = `[
+  {"count": 2, "ecosystem": "deb", "namespace": "debian", "name": "curl", "version": "1:7.50.3-1", "arch": "i386", "pkgrel": "1:7.50.3-1"},
+  {"count": 1, "ecosystem": "pypi", "name": "Django", "version": "1.11.1"},
+  {"count": 1, "ecosystem": "2.3", "namespace": "a", "name": "microsoft", "version": "internet_explorer", "pkgrel": "8.0.6001", "edition": "beta"},
+  {"count": 3, "ecosystem": "/a", "namespace": "hp", "name": "insight_diagnostics", "version": "7.4.0.1570", "pkgrel": "-"}
+]`
+
+func TestLoadBaselineGuacIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GuacIDs.json")
+	if err := os.WriteFile(path, []byte(baselineGuacIDs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGuacIDs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, _, renamed := MigrateGuacIDs(loaded, nil)
+
+	_, _, fresh, _ := ProcessIdentifiers(zap.NewNop(), &Identifiers{
+		Packages: []*model.Package{
+			{Type: "deb", Namespaces: []*model.PackageNamespace{{Namespace: "debian", Names: []*model.PackageName{{Name: "curl", Versions: []*model.PackageVersion{{
+				Version:    "1:7.50.3-1",
+				Qualifiers: []*model.PackageQualifier{{Key: "arch", Value: "i386"}},
+			}}}}}}},
+			{Type: "pypi", Namespaces: []*model.PackageNamespace{{Names: []*model.PackageName{{Name: "Django", Versions: []*model.PackageVersion{{Version: "1.11.1"}}}}}}},
+		},
+		HasMetadatas: []*model.HasMetadata{
+			{ID: "1", Key: "cpe", Value: "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*"},
+			{ID: "2", Key: "cpe", Value: "cpe:/a:hp:insight_diagnostics:7.4.0.1570:-"},
+		},
+	})
+
+	if len(migrated) != len(fresh) || len(renamed) != len(fresh) {
+		t.Fatalf("migrated %d GuacIDs, renaming %d; want the %d of a fresh extract", len(migrated), len(renamed), len(fresh))
+	}
+	for digest, want := range fresh {
+		got, ok := migrated[digest]
+		if !ok {
+			t.Errorf("no migrated GuacID for %s %s %s", want.Kind, want.Name, digest)
+			continue
+		}
+		if got.Kind != want.Kind || got.DigestVersion != schemas.GuacIDDigestVersion {
+			t.Errorf("migrated GuacID %s has kind %q, digest version %d; want %q, %d", digest, got.Kind, got.DigestVersion, want.Kind, schemas.GuacIDDigestVersion)
+		}
+	}
+	for legacy, digest := range renamed {
+		if _, ok := loaded[legacy]; !ok {
+			t.Errorf("renamed %s, which is not a loaded legacy digest", legacy)
+		}
+		if _, ok := fresh[digest]; !ok {
+			t.Errorf("renamed %s to %s, which a fresh extract does not have", legacy, digest)
+		}
+	}
+	if count := migrated[findDigest(fresh, "insight_diagnostics")].Count; count != 3 {
+		t.Errorf("migrated count = %d; want the saved 3", count)
+	}
+}
+
+func TestUpgradeLegacyGuacIDInvalid(t *testing.T) {
+	tests := []schemas.GuacID{
+		{Name: "curl"},
+		{Ecosystem: "2.3", Namespace: "a", Name: "hp", Version: "insight_diagnostics", PkgRel: "7.4.0.1570", Other: []string{"x64"}},
+		{Ecosystem: "npm", Name: "left-pad", Other: []string{"x64"}},
+	}
+
+	for _, legacy := range tests {
+		if _, err := schemas.UpgradeLegacyGuacID(legacy); err == nil {
+			t.Errorf("UpgradeLegacyGuacID(%+v) succeeded; want an error", legacy)
+		}
+	}
+}
+
+func findDigest(GuacIDs map[string]schemas.GuacID, name string) string {
+	for digest, guacID := range GuacIDs {
+		if guacID.Name == name {
+			return digest
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package processidentifiers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"go-query/schemas"
+)
+
+// ExportedGuacID is one row of an export: a GuacID and the community its
+// vertex ended up in, if communities were given.
+type ExportedGuacID struct {
+	Digest    string `json:"digest"`
+	Kind      string `json:"kind"`
+	Ecosystem string `json:"ecosystem,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Arch      string `json:"arch,omitempty"`
+	Purl      string `json:"purl,omitempty"`
+	CPE       string `json:"cpe,omitempty"`
+	Count     int64  `json:"count"`
+	Community string `json:"community,omitempty"`
+}
+
+var exportCSVHeader = []string{"digest", "kind", "ecosystem", "namespace", "name", "version", "arch", "purl", "cpe", "count", "community"}
+
+// ExportGuacIDs writes one row per GuacID to w as csv or jsonl.
+func ExportGuacIDs(w io.Writer, format string, GuacIDs []schemas.GuacID, communities []schemas.Community) error {
+	communityOf := make(map[string]string)
+	for _, community := range communities {
+		for _, node := range community.Nodes {
+			communityOf[node] = community.CommunityID
+		}
+	}
+
+	rows := make([]ExportedGuacID, 0, len(GuacIDs))
+	for _, gID := range GuacIDs {
+		rows = append(rows, ExportedGuacID{
+			Digest:    gID.Digest,
+			Kind:      gID.Kind,
+			Ecosystem: gID.Ecosystem,
+			Namespace: gID.Namespace,
+			Name:      gID.Name,
+			Version:   gID.Version,
+			Arch:      gID.Arch,
+			Purl:      gID.CanonicalPurl,
+			CPE:       gID.CanonicalCPE,
+			Count:     gID.Count,
+			Community: communityOf[guacIDVertex(gID)],
+		})
+	}
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportCSVHeader); err != nil {
+			return fmt.Errorf("unable to write export %s", err)
+		}
+		for _, row := range rows {
+			record := []string{row.Digest, row.Kind, row.Ecosystem, row.Namespace, row.Name, row.Version, row.Arch, row.Purl, row.CPE, strconv.FormatInt(row.Count, 10), row.Community}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("unable to write export %s", err)
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("unable to write export %s", err)
+		}
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return fmt.Errorf("unable to write export %s", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown export format %s", format)
+	}
+	return nil
+}
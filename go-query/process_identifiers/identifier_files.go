@@ -0,0 +1,187 @@
+package processidentifiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"go-query/schemas"
+
+	"github.com/dominikbraun/graph"
+	"gopkg.in/yaml.v3"
+)
+
+// Each stage of the command line tool writes its result to a file the next
+// stage reads, so a later stage can be rerun without repeating the earlier
+// ones.
+
+// GuacIDList returns the GuacIDs sorted by digest, the order they are saved in.
+func GuacIDList(GuacIDs map[string]schemas.GuacID) []schemas.GuacID {
+	idlist := make([]schemas.GuacID, 0, len(GuacIDs))
+	for _, guacID := range GuacIDs {
+		idlist = append(idlist, guacID)
+	}
+	sort.Slice(idlist, func(i, j int) bool {
+		return idlist[i].Digest < idlist[j].Digest
+	})
+	return idlist
+}
+
+// SaveGuacIDs writes GuacIDs as the list LoadGuacIDs reads.
+func SaveGuacIDs(path string, GuacIDs map[string]schemas.GuacID) error {
+	return writeJSONFile(path, "GuacIDs", GuacIDList(GuacIDs))
+}
+
+func SaveGuacIDLinks(path string, links []schemas.GuacIDLink) error {
+	return writeJSONFile(path, "GuacID links", links)
+}
+
+// SaveDigestMap writes the map from old GuacID digests to new ones that
+// MigrateGuacIDs returns.
+func SaveDigestMap(path string, renamed map[string]string) error {
+	return writeJSONFile(path, "GuacID digest map", renamed)
+}
+
+// LoadAliasDictionary reads an alias dictionary, as JSON if the file ends in
+// .json and as YAML otherwise.
+func LoadAliasDictionary(path string) (*schemas.AliasDictionary, error) {
+	file := schemas.AliasDictionary{}
+	if filepath.Ext(path) == ".json" {
+		if err := readJSONFile(path, "alias dictionary", &file); err != nil {
+			return nil, err
+		}
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read alias dictionary %s", err)
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("unable to parse alias dictionary %s", err)
+		}
+	}
+
+	aliases, err := schemas.NewAliasDictionary(file.Entities)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias dictionary %s", err)
+	}
+	return aliases, nil
+}
+
+// SaveAliasDictionary writes a dictionary LoadAliasDictionary reads back, in
+// the format the extension of path asks for.
+func SaveAliasDictionary(path string, aliases *schemas.AliasDictionary) error {
+	if filepath.Ext(path) == ".json" {
+		return writeJSONFile(path, "alias dictionary", aliases)
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(aliases); err != nil {
+		return fmt.Errorf("unable to marshal alias dictionary %s", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("unable to write alias dictionary %s", err)
+	}
+	return nil
+}
+
+// guacIDGraphFile is the on-disk form of a GuacID graph. Edges keep the
+// GuacIDEdge they were added with.
+type guacIDGraphFile struct {
+	Nodes []*schemas.GuacIDNode `json:"nodes"`
+	Edges []schemas.GuacIDEdge  `json:"edges"`
+}
+
+func SaveGuacIDGraph(path string, guacIdGraph graph.Graph[string, *schemas.GuacIDNode]) error {
+	adjMap, err := guacIdGraph.AdjacencyMap()
+	if err != nil {
+		return fmt.Errorf("unable to read GuacID graph %s", err)
+	}
+
+	file := guacIDGraphFile{}
+	for nodeID := range adjMap {
+		node, err := guacIdGraph.Vertex(nodeID)
+		if err != nil {
+			return fmt.Errorf("unable to read GuacID graph vertex %s", err)
+		}
+		file.Nodes = append(file.Nodes, node)
+
+		for _, edge := range adjMap[nodeID] {
+			guacIDEdge, _ := edge.Properties.Data.(schemas.GuacIDEdge)
+			guacIDEdge.Source, guacIDEdge.Target = edge.Source, edge.Target
+			file.Edges = append(file.Edges, guacIDEdge)
+		}
+	}
+	sort.Slice(file.Nodes, func(i, j int) bool {
+		return file.Nodes[i].NodeID < file.Nodes[j].NodeID
+	})
+	sort.Slice(file.Edges, func(i, j int) bool {
+		if file.Edges[i].Source != file.Edges[j].Source {
+			return file.Edges[i].Source < file.Edges[j].Source
+		}
+		return file.Edges[i].Target < file.Edges[j].Target
+	})
+
+	return writeJSONFile(path, "GuacID graph", file)
+}
+
+// LoadGuacIDGraph rebuilds a graph written by SaveGuacIDGraph.
+func LoadGuacIDGraph(path string) (graph.Graph[string, *schemas.GuacIDNode], error) {
+	file := guacIDGraphFile{}
+	if err := readJSONFile(path, "GuacID graph", &file); err != nil {
+		return nil, err
+	}
+
+	guacIdGraph := graph.New(schemas.GuacIDNodeID, graph.Directed())
+	for _, node := range file.Nodes {
+		if err := guacIdGraph.AddVertex(node); err != nil && err != graph.ErrVertexAlreadyExists {
+			return nil, fmt.Errorf("unable to add vertex %s: %s", node.NodeID, err)
+		}
+	}
+	for _, edge := range file.Edges {
+		err := guacIdGraph.AddEdge(edge.Source, edge.Target, graph.EdgeData(schemas.GuacIDEdge{EdgeID: edge.EdgeID, Counter: edge.Counter, Weight: edge.Weight}))
+		if err != nil && err != graph.ErrEdgeAlreadyExists {
+			return nil, fmt.Errorf("unable to add edge %s -> %s: %s", edge.Source, edge.Target, err)
+		}
+	}
+	return guacIdGraph, nil
+}
+
+func SaveCommunities(path string, communities []schemas.Community) error {
+	return writeJSONFile(path, "communities", communities)
+}
+
+// LoadCommunities reads communities written by SaveCommunities. Only their
+// node lists are kept, not the subgraphs they were detected in.
+func LoadCommunities(path string) ([]schemas.Community, error) {
+	communities := []schemas.Community{}
+	if err := readJSONFile(path, "communities", &communities); err != nil {
+		return nil, err
+	}
+	return communities, nil
+}
+
+func writeJSONFile(path, what string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s %s", what, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("unable to write %s %s", what, err)
+	}
+	return nil
+}
+
+func readJSONFile(path, what string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s %s", what, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unable to parse %s %s", what, err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package processidentifiers
+
+import (
+	"slices"
+
+	"go-query/schemas"
+
+	"github.com/dominikbraun/graph"
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+	"go.uber.org/zap"
+)
+
+// guacIDLinks collects links keyed by source, target and kind, counting each
+// piece of evidence once even when it is reachable through several nodes.
+type guacIDLinks struct {
+	links    map[string]*schemas.GuacIDLink
+	order    []string
+	evidence map[string]bool
+}
+
+func newGuacIDLinks() *guacIDLinks {
+	return &guacIDLinks{
+		links:    make(map[string]*schemas.GuacIDLink),
+		evidence: make(map[string]bool),
+	}
+}
+
+func (l *guacIDLinks) add(source, target, kind, evidence string) {
+	if source == target {
+		return
+	}
+	key := source + "|" + target + "|" + kind
+	if evidence != "" {
+		if l.evidence[key+"|"+evidence] {
+			return
+		}
+		l.evidence[key+"|"+evidence] = true
+	}
+
+	link, exists := l.links[key]
+	if !exists {
+		link = &schemas.GuacIDLink{Source: source, Target: target, Kind: kind}
+		l.links[key] = link
+		l.order = append(l.order, key)
+	}
+	link.Count++
+	if evidence != "" {
+		link.Evidence = append(link.Evidence, evidence)
+	}
+}
+
+// seed restores a link from an earlier run along with its evidence.
+func (l *guacIDLinks) seed(link schemas.GuacIDLink) {
+	key := link.Source + "|" + link.Target + "|" + link.Kind
+	if _, exists := l.links[key]; !exists {
+		l.order = append(l.order, key)
+	}
+	stored := link
+	stored.Evidence = append([]string{}, link.Evidence...)
+	l.links[key] = &stored
+	for _, evidence := range link.Evidence {
+		l.evidence[key+"|"+evidence] = true
+	}
+}
+
+// merge adds a saved link, combining it with a link between the same GuacIDs
+// that is already there.
+func (l *guacIDLinks) merge(link schemas.GuacIDLink) {
+	key := link.Source + "|" + link.Target + "|" + link.Kind
+	existing, exists := l.links[key]
+	if !exists {
+		l.seed(link)
+		return
+	}
+	if len(link.Evidence) == 0 {
+		existing.Count += link.Count
+		return
+	}
+	for _, evidence := range link.Evidence {
+		l.add(link.Source, link.Target, link.Kind, evidence)
+	}
+}
+
+func (l *guacIDLinks) list() []schemas.GuacIDLink {
+	links := []schemas.GuacIDLink{}
+	for _, key := range l.order {
+		links = append(links, *l.links[key])
+	}
+	return links
+}
+
+func (p *IdentifierProcessor) processLinks(ids *Identifiers) {
+	for _, occurrence := range ids.Occurrences {
+		p.addOccurrenceLinks(occurrence)
+	}
+
+	for _, hasSBOM := range ids.HasSBOMs {
+		for _, occurrence := range hasSBOM.IncludedOccurrences {
+			p.addOccurrenceLinks(occurrence)
+		}
+
+		var subjectDigests []string
+		switch subject := hasSBOM.Subject.(type) {
+		case *model.Package:
+			subjectDigests = p.packageGuacIDDigests(subject)
+		case *model.Artifact:
+			subjectDigests = []string{artifactGuacIDDigest(subject)}
+		}
+
+		// subjects of the same SBOM document, possibly seen in earlier batches
+		document := hasSBOM.Algorithm + ":" + hasSBOM.Digest
+		for _, subjectDigest := range subjectDigests {
+			for _, earlier := range p.sbomSubjects[document] {
+				p.links.add(earlier, subjectDigest, schemas.GuacIDLinkSBOMSubject, document)
+			}
+			p.sbomSubjects[document] = append(p.sbomSubjects[document], subjectDigest)
+		}
+	}
+
+	for _, hasSLSA := range ids.HasSLSAs {
+		if hasSLSA.Subject == nil || hasSLSA.Slsa == nil {
+			continue
+		}
+		subjectDigest := artifactGuacIDDigest(hasSLSA.Subject)
+		for _, material := range hasSLSA.Slsa.BuiltFrom {
+			p.links.add(subjectDigest, artifactGuacIDDigest(material), schemas.GuacIDLinkSLSABuiltFrom, hasSLSA.ID)
+		}
+	}
+}
+
+// addSubjectLinks links the package or artifact a HasMetadata is attached to
+// with the GuacID it names.
+func (p *IdentifierProcessor) addSubjectLinks(subject model.PackageSourceOrArtifact, digest, kind, evidence string) {
+	var subjectDigests []string
+	switch s := subject.(type) {
+	case *model.Package:
+		subjectDigests = p.packageGuacIDDigests(s)
+	case *model.Artifact:
+		subjectDigests = []string{artifactGuacIDDigest(s)}
+	}
+	for _, subjectDigest := range subjectDigests {
+		p.links.add(subjectDigest, digest, kind, evidence)
+	}
+}
+
+func (p *IdentifierProcessor) addOccurrenceLinks(occurrence *model.IsOccurrence) {
+	if occurrence == nil || occurrence.Artifact == nil {
+		return
+	}
+	pkg, ok := occurrence.Subject.(*model.Package)
+	if !ok {
+		return
+	}
+	artifactDigest := artifactGuacIDDigest(occurrence.Artifact)
+	for _, purlDigest := range p.packageGuacIDDigests(pkg) {
+		p.links.add(purlDigest, artifactDigest, schemas.GuacIDLinkOccurrence, occurrence.ID)
+	}
+}
+
+func artifactGuacIDDigest(artifact *model.Artifact) string {
+	return getGuacIdDigest(schemas.ConvertArtifactToGuacID(schemas.Artifact{
+		Algorithm: artifact.Algorithm,
+		Digest:    artifact.Digest,
+	}))
+}
+
+// packageGuacIDDigests returns the digests of the purl GuacIDs of a package
+// tree, resolved through the same aliases as the GuacIDs themselves.
+func (p *IdentifierProcessor) packageGuacIDDigests(pkg *model.Package) []string {
+	digests := []string{}
+	for _, leaf := range packageLeaves(pkg) {
+		digests = append(digests, getGuacIdDigest(p.Aliases.ConvertPurlToGuacID(leaf.Purl)))
+	}
+	return digests
+}
+
+// guacIDVertex is the vertex CreateGuacIDGraph uses to stand for a GuacID, or
+// "" when the GuacID has none.
+func guacIDVertex(gID schemas.GuacID) string {
+	switch gID.Kind {
+	case schemas.GuacIDKindArtifact, schemas.GuacIDKindGitoid:
+		return "Digest|" + gID.Digest
+	case schemas.GuacIDKindSWID:
+		return "SWID|" + gID.Name
+	}
+	if gID.Name == "" {
+		return ""
+	}
+	return "Name|" + gID.Name
+}
+
+// DefaultGraphLinkKinds are the link kinds AddGuacIDLinkEdges turns into edges
+// unless told otherwise: the ones that observe two identifiers naming the same
+// software. persistent_id and metadata_subject are among them, since the
+// document that attached the gitoid, SWID or CPE to a package said so, and
+// without them gitoid and SWID vertices would have no edges at all.
+// Provenance such as slsa_built_from joins different software and is left
+// out, as are inferred links such as cpe_purl_match until they have been
+// reviewed.
+var DefaultGraphLinkKinds = []string{
+	schemas.GuacIDLinkOccurrence,
+	schemas.GuacIDLinkSBOMSubject,
+	schemas.GuacIDLinkPersistentID,
+	schemas.GuacIDLinkMetadataSubject,
+}
+
+// AddGuacIDLinkEdges adds an edge for every link of one of kinds whose two
+// GuacIDs are in the graph, joining identifiers of different kinds that name
+// the same software.
+func AddGuacIDLinkEdges(logger *zap.Logger, guacIdGraph graph.Graph[string, *schemas.GuacIDNode], GuacIDs map[string]schemas.GuacID, links []schemas.GuacIDLink, kinds []string) {
+	skipped := make(map[string]int)
+	for _, link := range links {
+		if !slices.Contains(kinds, link.Kind) {
+			skipped[link.Kind]++
+			continue
+		}
+		source, sourceExists := GuacIDs[link.Source]
+		target, targetExists := GuacIDs[link.Target]
+		if !sourceExists || !targetExists {
+			continue
+		}
+		sourceVertex, targetVertex := guacIDVertex(source), guacIDVertex(target)
+		if sourceVertex == "" || targetVertex == "" || sourceVertex == targetVertex {
+			continue
+		}
+
+		err := guacIdGraph.AddEdge(sourceVertex, targetVertex, graph.EdgeData(schemas.GuacIDEdge{Counter: link.Count}))
+		if err != nil && err != graph.ErrEdgeAlreadyExists {
+			logger.Error(err.Error(), zap.String("Source", sourceVertex), zap.String("Target", targetVertex))
+		}
+	}
+	for kind, count := range skipped {
+		logger.Info("left out links of a kind not joined in the graph", zap.String("kind", kind), zap.Int("links", count))
+	}
+}
@@ -0,0 +1,42 @@
+package processidentifiers
+
+import (
+	"testing"
+
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+	"go.uber.org/zap"
+)
+
+// TestDefaultGraphLinkKinds checks that the gitoid, SWID and CPE a HasMetadata
+// attaches to a package are joined to it in the graph by default.
+func TestDefaultGraphLinkKinds(t *testing.T) {
+	pkg := &model.Package{ID: "1", Type: "npm", Namespaces: []*model.PackageNamespace{{ID: "2", Names: []*model.PackageName{{ID: "3", Name: "left-pad", Versions: []*model.PackageVersion{{ID: "4", Version: "1.3.0"}}}}}}}
+	_, _, GuacIDs, links := ProcessIdentifiers(zap.NewNop(), &Identifiers{
+		Packages: []*model.Package{pkg},
+		HasMetadatas: []*model.HasMetadata{
+			{ID: "5", Subject: pkg, Key: "gitoid", Value: "gitoid:blob:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64"},
+			{ID: "6", Subject: pkg, Key: "swid", Value: "swid:com.example.left-pad-1.3.0"},
+			{ID: "7", Subject: pkg, Key: "cpe", Value: "cpe:2.3:a:left-pad_project:left_pad:1.3.0:*:*:*:*:node.js:*:*"},
+		},
+	})
+
+	guacIdGraph, err := CreateGuacIDGraph(zap.NewNop(), GuacIDList(GuacIDs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	AddGuacIDLinkEdges(zap.NewNop(), guacIdGraph, GuacIDs, links, DefaultGraphLinkKinds)
+
+	predecessors, err := guacIdGraph.PredecessorMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, vertex := range []string{
+		"Digest|gitoid:blob:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64",
+		"SWID|com.example.left-pad-1.3.0",
+		"Name|left_pad",
+	} {
+		if _, ok := predecessors[vertex][guacIDVertex(GuacIDs[findDigest(GuacIDs, "left-pad")])]; !ok {
+			t.Errorf("%s is not joined to its package; edges into it: %v", vertex, predecessors[vertex])
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package processidentifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/guacsec/guac/pkg/assembler/backends"
+	_ "github.com/guacsec/guac/pkg/assembler/backends/keyvalue"
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+	"go.uber.org/zap"
+)
+
+// IdentifierSource yields the GUAC nodes identifiers are extracted from.
+type IdentifierSource interface {
+	Artifacts(ctx context.Context) ([]*model.Artifact, error)
+	HasMetadata(ctx context.Context) ([]*model.HasMetadata, error)
+	Packages(ctx context.Context) ([]*model.Package, error)
+	IsOccurrence(ctx context.Context) ([]*model.IsOccurrence, error)
+	HasSBOM(ctx context.Context) ([]*model.HasSbom, error)
+	HasSLSA(ctx context.Context) ([]*model.HasSlsa, error)
+}
+
+// BackendSource reads identifiers from any GUAC backend, such as ent or the
+// in-memory keyvalue backend. When HasMetadataSince is set only metadata
+// recorded at or after it is fetched. HasMetadata is the only node the
+// backends can filter by time; see Watermark.
+type BackendSource struct {
+	Backend          backends.Backend
+	HasMetadataSince *time.Time
+}
+
+func NewBackendSource(be backends.Backend) *BackendSource {
+	return &BackendSource{Backend: be}
+}
+
+func SetupKeyValueBackendForIdentifiers(ctx context.Context) (backends.Backend, error) {
+	be, err := backends.Get("keyvalue", ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get keyvalue backend %s", err)
+	}
+	return be, nil
+}
+
+func (s *BackendSource) Artifacts(ctx context.Context) ([]*model.Artifact, error) {
+	return s.Backend.Artifacts(ctx, &model.ArtifactSpec{})
+}
+
+func (s *BackendSource) HasMetadata(ctx context.Context) ([]*model.HasMetadata, error) {
+	return s.Backend.HasMetadata(ctx, &model.HasMetadataSpec{Since: s.HasMetadataSince})
+}
+
+func (s *BackendSource) Packages(ctx context.Context) ([]*model.Package, error) {
+	return s.Backend.Packages(ctx, &model.PkgSpec{})
+}
+
+func (s *BackendSource) IsOccurrence(ctx context.Context) ([]*model.IsOccurrence, error) {
+	return s.Backend.IsOccurrence(ctx, &model.IsOccurrenceSpec{})
+}
+
+func (s *BackendSource) HasSBOM(ctx context.Context) ([]*model.HasSbom, error) {
+	return s.Backend.HasSBOM(ctx, &model.HasSBOMSpec{})
+}
+
+func (s *BackendSource) HasSLSA(ctx context.Context) ([]*model.HasSlsa, error) {
+	return s.Backend.HasSlsa(ctx, &model.HasSLSASpec{})
+}
+
+// SBOMDirSource reads identifiers from a directory of SBOM files. The
+// directory is only read once, on first use.
+type SBOMDirSource struct {
+	Dir    string
+	logger *zap.Logger
+
+	ids *Identifiers
+}
+
+func NewSBOMDirSource(logger *zap.Logger, dir string) *SBOMDirSource {
+	return &SBOMDirSource{Dir: dir, logger: logger}
+}
+
+func (s *SBOMDirSource) load() (*Identifiers, error) {
+	if s.ids != nil {
+		return s.ids, nil
+	}
+	ids, err := GetAllIdentifiersFromSBOMs(s.logger, s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	s.ids = ids
+	return ids, nil
+}
+
+func (s *SBOMDirSource) Artifacts(ctx context.Context) ([]*model.Artifact, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.Artifacts, nil
+}
+
+func (s *SBOMDirSource) HasMetadata(ctx context.Context) ([]*model.HasMetadata, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.HasMetadatas, nil
+}
+
+func (s *SBOMDirSource) Packages(ctx context.Context) ([]*model.Package, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.Packages, nil
+}
+
+func (s *SBOMDirSource) IsOccurrence(ctx context.Context) ([]*model.IsOccurrence, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.Occurrences, nil
+}
+
+func (s *SBOMDirSource) HasSBOM(ctx context.Context) ([]*model.HasSbom, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.HasSBOMs, nil
+}
+
+func (s *SBOMDirSource) HasSLSA(ctx context.Context) ([]*model.HasSlsa, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.HasSLSAs, nil
+}
+
+// identifierSnapshot is the on-disk form of everything an IdentifierSource
+// returned, so a run can be repeated without the original source.
+type identifierSnapshot struct {
+	Artifacts    []*model.Artifact  `json:"artifacts"`
+	HasMetadatas []jsonHasMetadata  `json:"hasMetadatas"`
+	Packages     []*model.Package   `json:"packages"`
+	Occurrences  []jsonIsOccurrence `json:"occurrences"`
+	HasSBOMs     []jsonHasSBOM      `json:"hasSBOMs"`
+	HasSLSAs     []*model.HasSlsa   `json:"hasSLSAs"`
+}
+
+// SnapshotSource reads identifiers from a file written by WriteSnapshot. The
+// file is only read once, on first use.
+type SnapshotSource struct {
+	Path string
+
+	ids *Identifiers
+}
+
+func NewSnapshotSource(path string) *SnapshotSource {
+	return &SnapshotSource{Path: path}
+}
+
+func (s *SnapshotSource) load() (*Identifiers, error) {
+	if s.ids != nil {
+		return s.ids, nil
+	}
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read snapshot %s", err)
+	}
+	snapshot := identifierSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("unable to parse snapshot %s", err)
+	}
+
+	ids := &Identifiers{
+		Artifacts: snapshot.Artifacts,
+		Packages:  snapshot.Packages,
+		HasSLSAs:  snapshot.HasSLSAs,
+	}
+	for _, stored := range snapshot.HasMetadatas {
+		hasMetadata, err := stored.toModel()
+		if err != nil {
+			return nil, err
+		}
+		ids.HasMetadatas = append(ids.HasMetadatas, hasMetadata)
+	}
+	for _, stored := range snapshot.Occurrences {
+		occurrence, err := stored.toModel()
+		if err != nil {
+			return nil, err
+		}
+		ids.Occurrences = append(ids.Occurrences, occurrence)
+	}
+	for _, stored := range snapshot.HasSBOMs {
+		hasSBOM, err := stored.toModel()
+		if err != nil {
+			return nil, err
+		}
+		ids.HasSBOMs = append(ids.HasSBOMs, hasSBOM)
+	}
+
+	s.ids = ids
+	return ids, nil
+}
+
+func (s *SnapshotSource) Artifacts(ctx context.Context) ([]*model.Artifact, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.Artifacts, nil
+}
+
+func (s *SnapshotSource) HasMetadata(ctx context.Context) ([]*model.HasMetadata, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.HasMetadatas, nil
+}
+
+func (s *SnapshotSource) Packages(ctx context.Context) ([]*model.Package, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.Packages, nil
+}
+
+func (s *SnapshotSource) IsOccurrence(ctx context.Context) ([]*model.IsOccurrence, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.Occurrences, nil
+}
+
+func (s *SnapshotSource) HasSBOM(ctx context.Context) ([]*model.HasSbom, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.HasSBOMs, nil
+}
+
+func (s *SnapshotSource) HasSLSA(ctx context.Context) ([]*model.HasSlsa, error) {
+	ids, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return ids.HasSLSAs, nil
+}
+
+// WriteSnapshot saves identifiers to path for later use with SnapshotSource.
+func WriteSnapshot(path string, ids *Identifiers) error {
+	snapshot := identifierSnapshot{
+		Artifacts: ids.Artifacts,
+		Packages:  ids.Packages,
+		HasSLSAs:  ids.HasSLSAs,
+	}
+
+	for _, hasMetadata := range ids.HasMetadatas {
+		stored, err := fromHasMetadata(hasMetadata)
+		if err != nil {
+			return err
+		}
+		snapshot.HasMetadatas = append(snapshot.HasMetadatas, stored)
+	}
+	for _, occurrence := range ids.Occurrences {
+		stored, err := fromIsOccurrence(occurrence)
+		if err != nil {
+			return err
+		}
+		snapshot.Occurrences = append(snapshot.Occurrences, stored)
+	}
+	for _, hasSBOM := range ids.HasSBOMs {
+		stored, err := fromHasSBOM(hasSBOM)
+		if err != nil {
+			return err
+		}
+		snapshot.HasSBOMs = append(snapshot.HasSBOMs, stored)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("unable to marshal snapshot %s", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("unable to write snapshot %s", err)
+	}
+	return nil
+}
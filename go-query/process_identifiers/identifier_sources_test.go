@@ -0,0 +1,80 @@
+package processidentifiers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+	"go.uber.org/zap"
+
+	"go-query/schemas"
+)
+
+// TestBackendSource ingests a package, an artifact it occurs as and a CPE of
+// the package into the in-memory keyvalue backend, and extracts their GuacIDs
+// and links through a BackendSource.
+func TestBackendSource(t *testing.T) {
+	ctx := context.Background()
+	be, err := SetupKeyValueBackendForIdentifiers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespace, version := "debian", "1:7.50.3-1"
+	pkg := &model.IDorPkgInput{PackageInput: &model.PkgInputSpec{
+		Type:       "deb",
+		Namespace:  &namespace,
+		Name:       "curl",
+		Version:    &version,
+		Qualifiers: []*model.PackageQualifierInputSpec{{Key: "arch", Value: "amd64"}},
+	}}
+	artifact := &model.IDorArtifactInput{ArtifactInput: &model.ArtifactInputSpec{
+		Algorithm: "sha256",
+		Digest:    "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}}
+	if _, err := be.IngestPackage(ctx, *pkg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.IngestArtifact(ctx, artifact); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.IngestOccurrence(ctx, model.PackageOrSourceInput{Package: pkg}, *artifact, model.IsOccurrenceInputSpec{
+		Justification: "test",
+		Origin:        "test",
+		Collector:     "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.IngestHasMetadata(ctx, model.PackageSourceOrArtifactInput{Package: pkg}, &model.MatchFlags{Pkg: model.PkgMatchTypeSpecificVersion}, model.HasMetadataInputSpec{
+		Key:         "cpe",
+		Value:       "cpe:2.3:a:haxx:curl:7.50.3:*:*:*:*:*:*:*",
+		Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Origin:      "test",
+		Collector:   "test",
+		DocumentRef: "sbom-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := GetAllIdentifiers(ctx, NewBackendSource(be))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, GuacIDs, links := ProcessIdentifiers(zap.NewNop(), ids)
+
+	kinds := make(map[string]int)
+	for _, guacID := range GuacIDs {
+		kinds[guacID.Kind]++
+	}
+	if kinds[schemas.GuacIDKindPurl] != 1 || kinds[schemas.GuacIDKindArtifact] != 1 || kinds[schemas.GuacIDKindCPE] != 1 {
+		t.Errorf("GuacIDs of kinds %v; want one purl, artifact and cpe", kinds)
+	}
+	linkKinds := make(map[string]int)
+	for _, link := range links {
+		linkKinds[link.Kind]++
+	}
+	if linkKinds[schemas.GuacIDLinkOccurrence] != 1 || linkKinds[schemas.GuacIDLinkMetadataSubject] != 1 {
+		t.Errorf("links of kinds %v; want one occurrence and one metadata_subject", linkKinds)
+	}
+}
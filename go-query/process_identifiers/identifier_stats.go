@@ -0,0 +1,73 @@
+package processidentifiers
+
+import (
+	"sort"
+
+	"go-query/schemas"
+)
+
+// GuacIDStats summarizes the output of an extract run.
+type GuacIDStats struct {
+	GuacIDs          int              `json:"guacIDs"`
+	Occurrences      int64            `json:"occurrences"`
+	ByKind           map[string]int   `json:"byKind"`
+	ByEcosystem      map[string]int   `json:"byEcosystem"`
+	ByProvenance     map[string]int   `json:"byProvenance"`
+	AliasesFired     map[string]int   `json:"aliasesFired,omitempty"`
+	Links            int              `json:"links"`
+	LinksByKind      map[string]int   `json:"linksByKind"`
+	MostSeen         []schemas.GuacID `json:"mostSeen"`
+	Communities      int              `json:"communities,omitempty"`
+	LargestCommunity int              `json:"largestCommunity,omitempty"`
+}
+
+// SummarizeGuacIDs counts GuacIDs, links and communities, keeping the top
+// GuacIDs by Count. communities may be nil.
+func SummarizeGuacIDs(GuacIDs map[string]schemas.GuacID, links []schemas.GuacIDLink, communities []schemas.Community, top int) GuacIDStats {
+	stats := GuacIDStats{
+		GuacIDs:      len(GuacIDs),
+		ByKind:       make(map[string]int),
+		ByEcosystem:  make(map[string]int),
+		ByProvenance: make(map[string]int),
+		AliasesFired: make(map[string]int),
+		Links:        len(links),
+		LinksByKind:  make(map[string]int),
+		Communities:  len(communities),
+	}
+
+	for _, guacID := range GuacIDs {
+		stats.Occurrences += guacID.Count
+		stats.ByKind[guacID.Kind]++
+		if guacID.Ecosystem != "" {
+			stats.ByEcosystem[guacID.Ecosystem]++
+		}
+		for _, provenance := range guacID.Provenance {
+			stats.ByProvenance[provenance.Kind]++
+		}
+		for _, alias := range guacID.Aliases {
+			stats.AliasesFired[alias]++
+		}
+	}
+	for _, link := range links {
+		stats.LinksByKind[link.Kind]++
+	}
+	for _, community := range communities {
+		if community.Size > stats.LargestCommunity {
+			stats.LargestCommunity = community.Size
+		}
+	}
+
+	idlist := GuacIDList(GuacIDs)
+	sort.SliceStable(idlist, func(i, j int) bool {
+		return idlist[i].Count > idlist[j].Count
+	})
+	if len(idlist) > top {
+		idlist = idlist[:top]
+	}
+	for _, guacID := range idlist {
+		guacID.Provenance = nil
+		stats.MostSeen = append(stats.MostSeen, guacID)
+	}
+
+	return stats
+}
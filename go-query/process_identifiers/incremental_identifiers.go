@@ -0,0 +1,209 @@
+package processidentifiers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"go-query/schemas"
+
+	"go.uber.org/zap"
+)
+
+// Watermark is the state persisted next to GuacIDs.json between runs: the
+// latest time seen on each kind of node that has one. Only HasMetadata can be
+// filtered by time in the backend; the ent backend matches the KnownSince of
+// HasSBOMs and the FinishedOn of HasSLSAs exactly, so those are fetched again
+// on later runs and skipped by the processor when older than their
+// watermark. Packages, artifacts and occurrences have no time at all, and are
+// fetched again and skipped using the provenance already recorded on each
+// GuacID and the evidence on each link.
+type Watermark struct {
+	LastRun           time.Time  `json:"lastRun"`
+	HasMetadataSince  *time.Time `json:"hasMetadataSince,omitempty"`
+	HasSBOMKnownSince *time.Time `json:"hasSBOMKnownSince,omitempty"`
+	HasSLSAFinishedOn *time.Time `json:"hasSLSAFinishedOn,omitempty"`
+	GuacIDCount       int        `json:"guacIDCount"`
+	LinkCount         int        `json:"linkCount"`
+}
+
+// WatermarkPath returns where the watermark for a GuacIDs file is kept.
+func WatermarkPath(guacIDsPath string) string {
+	return strings.TrimSuffix(guacIDsPath, ".json") + ".watermark.json"
+}
+
+// LoadWatermark returns nil without an error if no earlier run left one.
+func LoadWatermark(path string) (*Watermark, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read watermark %s", err)
+	}
+	watermark := &Watermark{}
+	if err := json.Unmarshal(data, watermark); err != nil {
+		return nil, fmt.Errorf("unable to parse watermark %s", err)
+	}
+	return watermark, nil
+}
+
+func SaveWatermark(path string, watermark *Watermark) error {
+	data, err := json.MarshalIndent(watermark, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal watermark %s", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("unable to write watermark %s", err)
+	}
+	return nil
+}
+
+// LoadGuacIDs reads a GuacIDs.json file back into the map ProcessIdentifiers
+// returns, keyed by digest.
+func LoadGuacIDs(path string) (map[string]schemas.GuacID, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read GuacIDs %s", err)
+	}
+	idlist := []schemas.GuacID{}
+	if err := json.Unmarshal(data, &idlist); err != nil {
+		return nil, fmt.Errorf("unable to parse GuacIDs %s", err)
+	}
+
+	GuacIDs := make(map[string]schemas.GuacID)
+	for _, guacID := range idlist {
+		// GuacIDs saved without a kind are rebuilt into current ones, still
+		// keyed by their legacy digest until MigrateGuacIDs rekeys them
+		if schemas.IsLegacyGuacID(guacID) {
+			digest := guacID.Digest
+			if digest == "" {
+				digest = schemas.LegacyGuacIDDigest(guacID)
+			}
+			upgraded, err := schemas.UpgradeLegacyGuacID(guacID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load GuacIDs %s", err)
+			}
+			upgraded.Digest = digest
+			guacID = upgraded
+		}
+		// the saved digest is kept even when it is from an older digest
+		// version, so that the links saved with it still match
+		if guacID.Digest == "" {
+			guacID.Digest = getGuacIdDigest(guacID)
+		}
+		GuacIDs[guacID.Digest] = guacID
+	}
+	return GuacIDs, nil
+}
+
+func LoadGuacIDLinks(path string) ([]schemas.GuacIDLink, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read GuacID links %s", err)
+	}
+	links := []schemas.GuacIDLink{}
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("unable to parse GuacID links %s", err)
+	}
+	return links, nil
+}
+
+// Seed loads the results of an earlier run so that processing only adds what
+// is new to them. GuacIDs saved with an older digest version are migrated
+// first.
+func (p *IdentifierProcessor) Seed(GuacIDs map[string]schemas.GuacID, links []schemas.GuacIDLink, watermark *Watermark) {
+	GuacIDs, links, renamed := MigrateGuacIDs(GuacIDs, links)
+	if len(renamed) > 0 {
+		p.logger.Info("migrated GuacID digests", zap.Int("renamed", len(renamed)))
+	}
+
+	for digest, guacID := range GuacIDs {
+		for _, provenance := range guacID.Provenance {
+			p.seen[provenanceKey(digest, provenance)] = true
+		}
+		p.documents[digest] = provenanceDocuments(guacID.Provenance)
+		// GuacIDs saved before they carried canonical strings get them now
+		schemas.SetCanonicalStrings(&guacID)
+		p.GuacIDs[digest] = guacID
+	}
+	for _, link := range links {
+		p.links.seed(link)
+	}
+	if watermark != nil {
+		p.since = *watermark
+		p.hasMetadataSince = watermark.HasMetadataSince
+		p.hasSBOMKnownSince = watermark.HasSBOMKnownSince
+		p.hasSLSAFinishedOn = watermark.HasSLSAFinishedOn
+	}
+}
+
+// skipProcessed leaves out the nodes older than the watermark the processor
+// was seeded from and advances the watermark past the rest. Nodes without a
+// time are always kept.
+func (p *IdentifierProcessor) skipProcessed(ids *Identifiers) *Identifiers {
+	kept := *ids
+
+	kept.HasMetadatas = nil
+	for _, metadata := range ids.HasMetadatas {
+		if before(metadata.Timestamp, p.since.HasMetadataSince) {
+			continue
+		}
+		advance(&p.hasMetadataSince, metadata.Timestamp)
+		kept.HasMetadatas = append(kept.HasMetadatas, metadata)
+	}
+
+	kept.HasSBOMs = nil
+	for _, hasSBOM := range ids.HasSBOMs {
+		if before(hasSBOM.KnownSince, p.since.HasSBOMKnownSince) {
+			continue
+		}
+		advance(&p.hasSBOMKnownSince, hasSBOM.KnownSince)
+		kept.HasSBOMs = append(kept.HasSBOMs, hasSBOM)
+	}
+
+	kept.HasSLSAs = nil
+	for _, hasSLSA := range ids.HasSLSAs {
+		if hasSLSA.Slsa != nil && hasSLSA.Slsa.FinishedOn != nil {
+			if before(*hasSLSA.Slsa.FinishedOn, p.since.HasSLSAFinishedOn) {
+				continue
+			}
+			advance(&p.hasSLSAFinishedOn, *hasSLSA.Slsa.FinishedOn)
+		}
+		kept.HasSLSAs = append(kept.HasSLSAs, hasSLSA)
+	}
+
+	return &kept
+}
+
+// before reports whether t is before the watermark since. A zero time is
+// unknown and never before it.
+func before(t time.Time, since *time.Time) bool {
+	return since != nil && !t.IsZero() && t.Before(*since)
+}
+
+// advance moves the watermark latest up to t.
+func advance(latest **time.Time, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if *latest == nil || t.After(**latest) {
+		*latest = &t
+	}
+}
+
+// Watermark describes the state after everything processed so far.
+func (p *IdentifierProcessor) Watermark() *Watermark {
+	return &Watermark{
+		LastRun:           time.Now().UTC(),
+		HasMetadataSince:  p.hasMetadataSince,
+		HasSBOMKnownSince: p.hasSBOMKnownSince,
+		HasSLSAFinishedOn: p.hasSLSAFinishedOn,
+		GuacIDCount:       len(p.GuacIDs),
+		LinkCount:         len(p.links.order),
+	}
+}
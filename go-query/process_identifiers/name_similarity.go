@@ -0,0 +1,122 @@
+package processidentifiers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-query/schemas"
+	"go-query/similarity"
+
+	"github.com/dominikbraun/graph"
+	"go.uber.org/zap"
+)
+
+// NameSimilarityOptions configure AddNameSimilarityEdges.
+type NameSimilarityOptions struct {
+	Weights  similarity.Weights
+	MinScore float64
+	// MaxBlock leaves out names sharing a token with more names than this:
+	// a token as common as core or utils says little about any two of them,
+	// and comparing them all is quadratic.
+	MaxBlock int
+	// Candidates pairs the names worth scoring, such as LSHNameCandidates
+	// does. Left nil, names sharing a token or their form without
+	// separators are paired.
+	Candidates func(names []string) ([][2]string, error)
+}
+
+func DefaultNameSimilarityOptions() NameSimilarityOptions {
+	return NameSimilarityOptions{
+		Weights:  similarity.DefaultWeights(),
+		MinScore: 0.7,
+		MaxBlock: 200,
+	}
+}
+
+// AddNameSimilarityEdges joins Name vertices whose names are alike but not
+// identical with an edge weighted by their similarity. Only the candidate
+// pairs of names are compared. It returns how many edges were added.
+func AddNameSimilarityEdges(logger *zap.Logger, guacIdGraph graph.Graph[string, *schemas.GuacIDNode], GuacIDs []schemas.GuacID, opts NameSimilarityOptions) (int, error) {
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, gID := range GuacIDs {
+		vertex := guacIDVertex(gID)
+		if !strings.HasPrefix(vertex, "Name|") || seen[gID.Name] {
+			continue
+		}
+		seen[gID.Name] = true
+		names = append(names, gID.Name)
+	}
+
+	var candidates [][2]string
+	if opts.Candidates != nil {
+		var err error
+		if candidates, err = opts.Candidates(names); err != nil {
+			return 0, fmt.Errorf("unable to find name similarity candidates %s", err)
+		}
+	} else {
+		candidates = tokenBlockCandidates(logger, names, opts.MaxBlock)
+	}
+
+	added := 0
+	for _, candidate := range candidates {
+		a, b := candidate[0], candidate[1]
+		score := similarity.Compare(a, b).Score(opts.Weights)
+		if a == b || score < opts.MinScore {
+			continue
+		}
+		err := guacIdGraph.AddEdge("Name|"+a, "Name|"+b, graph.EdgeData(schemas.GuacIDEdge{Weight: score}))
+		if err != nil && err != graph.ErrEdgeAlreadyExists {
+			logger.Error(err.Error(), zap.String("Source", "Name|"+a), zap.String("Target", "Name|"+b))
+			continue
+		}
+		if err == nil {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// tokenBlockCandidates pairs the names sharing a token of at least three
+// letters or their form without separators, in a stable order.
+func tokenBlockCandidates(logger *zap.Logger, names []string, maxBlock int) [][2]string {
+	blocks := make(map[string][]string)
+	for _, name := range names {
+		keys := map[string]bool{"=" + similarity.StripSeparators(name): true}
+		for _, token := range similarity.Tokens(name) {
+			if len(token) >= 3 {
+				keys[token] = true
+			}
+		}
+		for key := range keys {
+			blocks[key] = append(blocks[key], name)
+		}
+	}
+
+	keys := make([]string, 0, len(blocks))
+	for key := range blocks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	candidates := [][2]string{}
+	paired := make(map[[2]string]bool)
+	for _, key := range keys {
+		block := blocks[key]
+		if len(block) > maxBlock {
+			logger.Debug("skipping name similarity block", zap.String("key", key), zap.Int("names", len(block)))
+			continue
+		}
+		sort.Strings(block)
+		for i, a := range block {
+			for _, b := range block[i+1:] {
+				if !paired[[2]string{a, b}] {
+					paired[[2]string{a, b}] = true
+					candidates = append(candidates, [2]string{a, b})
+				}
+			}
+		}
+	}
+	return candidates
+}
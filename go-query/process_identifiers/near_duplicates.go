@@ -0,0 +1,125 @@
+package processidentifiers
+
+import (
+	"fmt"
+	"sort"
+
+	"go-query/schemas"
+	"go-query/similarity"
+)
+
+// NearDuplicateOptions configure the MinHash LSH searches for near-duplicate
+// GuacIDs and names. Bands times Rows is the length of the signatures; sets
+// less alike than about (1/Bands)^(1/Rows) are unlikely to be found.
+type NearDuplicateOptions struct {
+	Bands      int
+	Rows       int
+	MinJaccard float64
+	// MaxBucket skips LSH buckets of more sets than this, which would make
+	// the search quadratic again; 0 keeps them all.
+	MaxBucket int
+	Seed      uint64
+}
+
+// DefaultNearDuplicateOptions find sets from a Jaccard index of about 0.5,
+// and keep those estimated at 0.6 or more.
+func DefaultNearDuplicateOptions() NearDuplicateOptions {
+	return NearDuplicateOptions{
+		Bands:      32,
+		Rows:       5,
+		MinJaccard: 0.6,
+		MaxBucket:  100,
+		Seed:       1,
+	}
+}
+
+// GuacIDFeatures is the set a GuacID is indexed by when searching for near
+// duplicates: the trigrams of its name, and its other attributes as
+// field=value. The kind is left out, so a CPE and a purl with the same
+// attributes are duplicates. Artifacts and gitoids are only their hash and
+// have no features.
+func GuacIDFeatures(gID schemas.GuacID) []string {
+	if gID.Kind == schemas.GuacIDKindArtifact || gID.Kind == schemas.GuacIDKindGitoid {
+		return nil
+	}
+
+	features := []string{}
+	for _, gram := range similarity.NGrams(gID.Name, 3) {
+		features = append(features, "name~"+gram)
+	}
+	fields := []struct{ field, value string }{
+		{"ecosystem", gID.Ecosystem},
+		{"namespace", gID.Namespace},
+		{"version", gID.Version},
+		{"epoch", gID.Epoch},
+		{"arch", gID.Arch},
+		{"subpath", gID.SubPath},
+		{"pkgrel", gID.PkgRel},
+		{"edition", gID.Edition},
+		{"language", gID.Language},
+		{"sw_edition", gID.SWEdition},
+		{"target_sw", gID.TargetSW},
+	}
+	for _, f := range fields {
+		if f.value != "" {
+			features = append(features, f.field+"="+f.value)
+		}
+	}
+	for _, other := range gID.Other {
+		features = append(features, "other="+other)
+	}
+	for key, value := range gID.Qualifiers {
+		features = append(features, "qualifier="+key+"="+value)
+	}
+	return features
+}
+
+// NearDuplicateLinks links the GuacIDs whose features are estimated to be at
+// least opts.MinJaccard alike, built in memory with a MinHash LSH index rather
+// than by comparing every pair.
+func NearDuplicateLinks(GuacIDs map[string]schemas.GuacID, opts NearDuplicateOptions) ([]schemas.GuacIDLink, error) {
+	index, err := similarity.NewLSHIndex(opts.Bands, opts.Rows, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+	// indexed in digest order, so the same GuacIDs always give the same
+	// links
+	for _, gID := range GuacIDList(GuacIDs) {
+		index.Add(gID.Digest, GuacIDFeatures(gID))
+	}
+
+	links := []schemas.GuacIDLink{}
+	for _, pair := range index.Pairs(opts.MinJaccard, opts.MaxBucket) {
+		links = append(links, schemas.GuacIDLink{
+			Source:   pair.A,
+			Target:   pair.B,
+			Kind:     schemas.GuacIDLinkNearDuplicate,
+			Count:    1,
+			Evidence: []string{fmt.Sprintf("estimated jaccard %.2f of name trigrams and attributes", pair.Jaccard)},
+			Score:    pair.Jaccard,
+		})
+	}
+	return links, nil
+}
+
+// LSHNameCandidates pairs the names whose trigrams are estimated to be at
+// least opts.MinJaccard alike. It finds candidates for
+// AddNameSimilarityEdges that share no token.
+func LSHNameCandidates(names []string, opts NearDuplicateOptions) ([][2]string, error) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+
+	index, err := similarity.NewLSHIndex(opts.Bands, opts.Rows, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sorted {
+		index.Add(name, similarity.NGrams(name, 3))
+	}
+
+	candidates := [][2]string{}
+	for _, pair := range index.Pairs(opts.MinJaccard, opts.MaxBucket) {
+		candidates = append(candidates, [2]string{pair.A, pair.B})
+	}
+	return candidates, nil
+}
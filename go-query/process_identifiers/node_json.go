@@ -0,0 +1,259 @@
+package processidentifiers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+)
+
+// The model types hold GraphQL unions as interfaces, which encoding/json can
+// write but not read back. The types below mirror them with each union kept
+// as raw JSON tagged by its __typename, the way GUAC exports them.
+
+type jsonHasMetadata struct {
+	ID            string          `json:"id"`
+	Subject       json.RawMessage `json:"subject"`
+	Key           string          `json:"key"`
+	Value         string          `json:"value"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Justification string          `json:"justification"`
+	Origin        string          `json:"origin"`
+	Collector     string          `json:"collector"`
+	DocumentRef   string          `json:"documentRef"`
+}
+
+type jsonIsOccurrence struct {
+	ID            string          `json:"id"`
+	Subject       json.RawMessage `json:"subject"`
+	Artifact      *model.Artifact `json:"artifact"`
+	Justification string          `json:"justification"`
+	Origin        string          `json:"origin"`
+	Collector     string          `json:"collector"`
+	DocumentRef   string          `json:"documentRef"`
+}
+
+type jsonHasSBOM struct {
+	ID                   string                `json:"id"`
+	Subject              json.RawMessage       `json:"subject"`
+	URI                  string                `json:"uri"`
+	Algorithm            string                `json:"algorithm"`
+	Digest               string                `json:"digest"`
+	DownloadLocation     string                `json:"downloadLocation"`
+	KnownSince           time.Time             `json:"knownSince"`
+	Origin               string                `json:"origin"`
+	Collector            string                `json:"collector"`
+	DocumentRef          string                `json:"documentRef"`
+	IncludedSoftware     []json.RawMessage     `json:"includedSoftware"`
+	IncludedDependencies []*model.IsDependency `json:"includedDependencies"`
+	IncludedOccurrences  []jsonIsOccurrence    `json:"includedOccurrences"`
+}
+
+func (j jsonHasMetadata) toModel() (*model.HasMetadata, error) {
+	subject, err := decodeNodeUnion(j.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode subject of %s: %s", j.ID, err)
+	}
+	hasMetadata := &model.HasMetadata{
+		ID:            j.ID,
+		Key:           j.Key,
+		Value:         j.Value,
+		Timestamp:     j.Timestamp,
+		Justification: j.Justification,
+		Origin:        j.Origin,
+		Collector:     j.Collector,
+		DocumentRef:   j.DocumentRef,
+	}
+	if subject, ok := subject.(model.PackageSourceOrArtifact); ok {
+		hasMetadata.Subject = subject
+	}
+	return hasMetadata, nil
+}
+
+func fromHasMetadata(hasMetadata *model.HasMetadata) (jsonHasMetadata, error) {
+	subject, err := encodeNodeUnion(hasMetadata.Subject)
+	if err != nil {
+		return jsonHasMetadata{}, fmt.Errorf("unable to encode subject of %s: %s", hasMetadata.ID, err)
+	}
+	return jsonHasMetadata{
+		ID:            hasMetadata.ID,
+		Subject:       subject,
+		Key:           hasMetadata.Key,
+		Value:         hasMetadata.Value,
+		Timestamp:     hasMetadata.Timestamp,
+		Justification: hasMetadata.Justification,
+		Origin:        hasMetadata.Origin,
+		Collector:     hasMetadata.Collector,
+		DocumentRef:   hasMetadata.DocumentRef,
+	}, nil
+}
+
+func (j jsonIsOccurrence) toModel() (*model.IsOccurrence, error) {
+	subject, err := decodeNodeUnion(j.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode subject of %s: %s", j.ID, err)
+	}
+	occurrence := &model.IsOccurrence{
+		ID:            j.ID,
+		Artifact:      j.Artifact,
+		Justification: j.Justification,
+		Origin:        j.Origin,
+		Collector:     j.Collector,
+		DocumentRef:   j.DocumentRef,
+	}
+	if subject, ok := subject.(model.PackageOrSource); ok {
+		occurrence.Subject = subject
+	}
+	return occurrence, nil
+}
+
+func fromIsOccurrence(occurrence *model.IsOccurrence) (jsonIsOccurrence, error) {
+	subject, err := encodeNodeUnion(occurrence.Subject)
+	if err != nil {
+		return jsonIsOccurrence{}, fmt.Errorf("unable to encode subject of %s: %s", occurrence.ID, err)
+	}
+	return jsonIsOccurrence{
+		ID:            occurrence.ID,
+		Subject:       subject,
+		Artifact:      occurrence.Artifact,
+		Justification: occurrence.Justification,
+		Origin:        occurrence.Origin,
+		Collector:     occurrence.Collector,
+		DocumentRef:   occurrence.DocumentRef,
+	}, nil
+}
+
+func (j jsonHasSBOM) toModel() (*model.HasSbom, error) {
+	subject, err := decodeNodeUnion(j.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode subject of %s: %s", j.ID, err)
+	}
+	hasSBOM := &model.HasSbom{
+		ID:                   j.ID,
+		URI:                  j.URI,
+		Algorithm:            j.Algorithm,
+		Digest:               j.Digest,
+		DownloadLocation:     j.DownloadLocation,
+		KnownSince:           j.KnownSince,
+		Origin:               j.Origin,
+		Collector:            j.Collector,
+		DocumentRef:          j.DocumentRef,
+		IncludedDependencies: j.IncludedDependencies,
+	}
+	if subject, ok := subject.(model.PackageOrArtifact); ok {
+		hasSBOM.Subject = subject
+	}
+
+	for _, raw := range j.IncludedSoftware {
+		software, err := decodeNodeUnion(raw)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode software of %s: %s", j.ID, err)
+		}
+		if software, ok := software.(model.PackageOrArtifact); ok {
+			hasSBOM.IncludedSoftware = append(hasSBOM.IncludedSoftware, software)
+		}
+	}
+	for _, included := range j.IncludedOccurrences {
+		occurrence, err := included.toModel()
+		if err != nil {
+			return nil, err
+		}
+		hasSBOM.IncludedOccurrences = append(hasSBOM.IncludedOccurrences, occurrence)
+	}
+	return hasSBOM, nil
+}
+
+func fromHasSBOM(hasSBOM *model.HasSbom) (jsonHasSBOM, error) {
+	subject, err := encodeNodeUnion(hasSBOM.Subject)
+	if err != nil {
+		return jsonHasSBOM{}, fmt.Errorf("unable to encode subject of %s: %s", hasSBOM.ID, err)
+	}
+	j := jsonHasSBOM{
+		ID:                   hasSBOM.ID,
+		Subject:              subject,
+		URI:                  hasSBOM.URI,
+		Algorithm:            hasSBOM.Algorithm,
+		Digest:               hasSBOM.Digest,
+		DownloadLocation:     hasSBOM.DownloadLocation,
+		KnownSince:           hasSBOM.KnownSince,
+		Origin:               hasSBOM.Origin,
+		Collector:            hasSBOM.Collector,
+		DocumentRef:          hasSBOM.DocumentRef,
+		IncludedDependencies: hasSBOM.IncludedDependencies,
+	}
+
+	for _, software := range hasSBOM.IncludedSoftware {
+		raw, err := encodeNodeUnion(software)
+		if err != nil {
+			return jsonHasSBOM{}, fmt.Errorf("unable to encode software of %s: %s", hasSBOM.ID, err)
+		}
+		j.IncludedSoftware = append(j.IncludedSoftware, raw)
+	}
+	for _, occurrence := range hasSBOM.IncludedOccurrences {
+		included, err := fromIsOccurrence(occurrence)
+		if err != nil {
+			return jsonHasSBOM{}, err
+		}
+		j.IncludedOccurrences = append(j.IncludedOccurrences, included)
+	}
+	return j, nil
+}
+
+// decodeNodeUnion decodes a GraphQL union member using its __typename.
+func decodeNodeUnion(raw json.RawMessage) (any, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var typename struct {
+		Typename string `json:"__typename"`
+	}
+	if err := json.Unmarshal(raw, &typename); err != nil {
+		return nil, err
+	}
+
+	var node any
+	switch typename.Typename {
+	case "Package":
+		node = &model.Package{}
+	case "Artifact":
+		node = &model.Artifact{}
+	case "Source":
+		node = &model.Source{}
+	default:
+		return nil, fmt.Errorf("unknown node type %q", typename.Typename)
+	}
+	if err := json.Unmarshal(raw, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// encodeNodeUnion is the inverse of decodeNodeUnion.
+func encodeNodeUnion(node any) (json.RawMessage, error) {
+	var typename string
+	switch n := node.(type) {
+	case nil:
+		return json.RawMessage("null"), nil
+	case *model.Package:
+		typename = "Package"
+	case *model.Artifact:
+		typename = "Artifact"
+	case *model.Source:
+		typename = "Source"
+	default:
+		return nil, fmt.Errorf("unknown node type %T", n)
+	}
+
+	data, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["__typename"], _ = json.Marshal(typename)
+	return json.Marshal(fields)
+}
@@ -95,6 +95,7 @@ func RecursiveCommunityDetection(g graph.Graph[string, *schemas.GuacIDNode]) []s
 				CommunityID: communityID,
 			
 				Size:        len(nodes),
+				Nodes:       nodes,
 				GraphSubset: &subgraph,
 			})
 			return
@@ -110,6 +111,7 @@ func RecursiveCommunityDetection(g graph.Graph[string, *schemas.GuacIDNode]) []s
 				CommunityID: communityID,
 	
 				Size:        len(nodes),
+				Nodes:       nodes,
 				GraphSubset: &subgraph,
 			})
 			return
@@ -2,10 +2,9 @@ package processidentifiers
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
-	"sort"
-	"strings"
+	"slices"
+	"time"
 
 	"go-query/helpers"
 	"go-query/schemas"
@@ -33,221 +32,426 @@ func SetupEntBackendForIdentifiers(opts *entbackend.BackendOptions) (context.Con
 	return ctx, be, nil
 }
 
-func GetAllIdentifiers(ctx context.Context, be backends.Backend) ([]*model.Artifact, []*model.HasMetadata, []*model.Package, error) {
+// Identifiers holds every GUAC node identifiers and the links between them
+// are extracted from.
+type Identifiers struct {
+	Artifacts    []*model.Artifact
+	HasMetadatas []*model.HasMetadata
+	Packages     []*model.Package
+	Occurrences  []*model.IsOccurrence
+	HasSBOMs     []*model.HasSbom
+	HasSLSAs     []*model.HasSlsa
+}
+
+func GetAllIdentifiers(ctx context.Context, src IdentifierSource) (*Identifiers, error) {
+
+	artifacts, err := src.Artifacts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get artifact list %s", err)
+	}
 
-	artifacts, err := be.Artifacts(ctx, &model.ArtifactSpec{})
+	hasMetadatas, err := src.HasMetadata(ctx)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("unable to get artifact list %s", err)
+		return nil, fmt.Errorf("unable to get hasMetadata list %s", err)
 	}
 
-	hasMetadatas, err := be.HasMetadata(ctx, &model.HasMetadataSpec{})
+	packages, err := src.Packages(ctx)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("unable to get hasMetadata list %s", err)
+		return nil, fmt.Errorf("unable to get package list %s", err)
 	}
 
-	packages, err := be.Packages(ctx, &model.PkgSpec{})
+	occurrences, err := src.IsOccurrence(ctx)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("unable to get package list %s", err)
+		return nil, fmt.Errorf("unable to get isOccurrence list %s", err)
 	}
 
-	return artifacts, hasMetadatas, packages, nil
+	hasSBOMs, err := src.HasSBOM(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get hasSBOM list %s", err)
+	}
+
+	hasSLSAs, err := src.HasSLSA(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get hasSLSA list %s", err)
+	}
+
+	return &Identifiers{
+		Artifacts:    artifacts,
+		HasMetadatas: hasMetadatas,
+		Packages:     packages,
+		Occurrences:  occurrences,
+		HasSBOMs:     hasSBOMs,
+		HasSLSAs:     hasSLSAs,
+	}, nil
 
 }
 
+// getGuacIdDigest returns the digest a GuacID is keyed by: the content digest
+// of an artifact or the gitoid, or the hash of the canonical encoding of everything else.
 func getGuacIdDigest(guacID schemas.GuacID) string {
-	if guacID.Digest != "" {
+	if guacID.Kind == schemas.GuacIDKindArtifact || guacID.Kind == schemas.GuacIDKindGitoid {
 		return guacID.Digest
 	}
+	return schemas.GuacIDDigest(guacID)
+}
+
+func ProcessIdentifiers(logger *zap.Logger, ids *Identifiers) ([]schemas.CPE, []schemas.Purl, map[string]schemas.GuacID, []schemas.GuacIDLink) {
 
-	var fields []string
+	processor := NewIdentifierProcessor(logger)
+	processor.KeepParsed = true
+	processor.Process(ids)
+
+	return processor.CPEs, processor.Purls, processor.GuacIDs, processor.Links()
+}
 
-	if guacID.Ecosystem != "" {
-		fields = append(fields, guacID.Ecosystem)
+// IdentifierProcessor turns GUAC nodes into GuacIDs and links a batch at a
+// time, so identifiers can be streamed through it page by page. The nodes of
+// a batch are not kept, and neither are parsed CPEs and purls unless
+// KeepParsed is set, but memory still grows with the database: besides the
+// GuacIDs, their provenance and links, the processor remembers every node it
+// counted, the documents each GuacID was seen in and the subjects of every
+// SBOM document, so that nothing seen again in a later batch is counted
+// twice.
+type IdentifierProcessor struct {
+	GuacIDs    map[string]schemas.GuacID
+	KeepParsed bool
+	CPEs       []schemas.CPE
+	Purls      []schemas.Purl
+	// Aliases resolves CPE vendors and products and purl namespaces and
+	// names onto canonical entities. It may be nil.
+	Aliases *schemas.AliasDictionary
+
+	logger       *zap.Logger
+	links        *guacIDLinks
+	sbomSubjects map[string][]string
+	seen         map[string]bool
+	// documents holds the documents each GuacID was seen in, by digest
+	documents map[string]map[string]bool
+
+	// since is the watermark of the run the processor was seeded from
+	since             Watermark
+	hasMetadataSince  *time.Time
+	hasSBOMKnownSince *time.Time
+	hasSLSAFinishedOn *time.Time
+}
+
+func NewIdentifierProcessor(logger *zap.Logger) *IdentifierProcessor {
+	return &IdentifierProcessor{
+		GuacIDs:      make(map[string]schemas.GuacID),
+		logger:       logger,
+		links:        newGuacIDLinks(),
+		sbomSubjects: make(map[string][]string),
+		seen:         make(map[string]bool),
+		documents:    make(map[string]map[string]bool),
 	}
-	if guacID.Namespace != "" {
-		fields = append(fields, guacID.Namespace)
+}
+
+func (p *IdentifierProcessor) Links() []schemas.GuacIDLink {
+	return p.links.list()
+}
+
+// Process adds every node in ids. Batches may hold any mix of node kinds.
+func (p *IdentifierProcessor) Process(ids *Identifiers) {
+	ids = p.skipProcessed(ids)
+
+	for _, artifact := range ids.Artifacts {
+		guacID := schemas.ConvertArtifactToGuacID(schemas.Artifact{
+			Algorithm: artifact.Algorithm,
+			Digest:    artifact.Digest,
+		})
+		p.add(guacID, schemas.GuacIDProvenance{
+			Kind: schemas.ProvenanceArtifact,
+			ID:   artifact.ID,
+		})
 	}
-	if guacID.Name != "" {
-		fields = append(fields, guacID.Name)
+
+	for _, metadata := range ids.HasMetadatas {
+		var guacID schemas.GuacID
+		switch metadata.Key {
+		case "cpe":
+			cpe, err := schemas.ParseCPE(metadata.Value)
+			if err != nil {
+				p.logger.Info("unable to parse", zap.String(metadata.Key, metadata.Value))
+				continue
+			}
+			if p.KeepParsed {
+				p.CPEs = append(p.CPEs, cpe)
+			}
+			guacID = p.Aliases.ConvertCPEToGuacID(cpe)
+		case "purl":
+			purl, err := schemas.ParsePurl(metadata.Value)
+			if err != nil {
+				p.logger.Info("unable to parse", zap.String(metadata.Key, metadata.Value))
+				continue
+			}
+			if p.KeepParsed {
+				p.Purls = append(p.Purls, purl)
+			}
+			guacID = p.Aliases.ConvertPurlToGuacID(purl)
+		case "gitoid", "omnibor":
+			gitoid, err := schemas.ParseGitoid(metadata.Value)
+			if err != nil {
+				p.logger.Info("unable to parse", zap.String(metadata.Key, metadata.Value))
+				continue
+			}
+			guacID = schemas.ConvertGitoidToGuacID(gitoid)
+		case "swid":
+			swid, err := schemas.ParseSWID(metadata.Value)
+			if err != nil {
+				p.logger.Info("unable to parse", zap.String(metadata.Key, metadata.Value))
+				continue
+			}
+			guacID = schemas.ConvertSWIDToGuacID(swid)
+		default:
+			continue
+		}
+
+		digest := p.add(guacID, schemas.GuacIDProvenance{
+			Kind:     schemas.ProvenanceHasMetadata,
+			ID:       metadata.ID,
+			Origin:   metadata.Origin,
+			Document: hasMetadataDocument(metadata),
+		})
+		switch guacID.Kind {
+		case schemas.GuacIDKindGitoid, schemas.GuacIDKindSWID:
+			p.addSubjectLinks(metadata.Subject, digest, schemas.GuacIDLinkPersistentID, metadata.ID)
+		case schemas.GuacIDKindCPE:
+			// the CPE and the purls of its subject name the same component,
+			// which is what aliases are learned from
+			p.addSubjectLinks(metadata.Subject, digest, schemas.GuacIDLinkMetadataSubject, metadata.ID)
+		}
+	}
+
+	for _, pkg := range ids.Packages {
+		for _, leaf := range packageLeaves(pkg) {
+			if p.KeepParsed {
+				p.Purls = append(p.Purls, leaf.Purl)
+			}
+			guacID := p.Aliases.ConvertPurlToGuacID(leaf.Purl)
+			p.add(guacID, schemas.GuacIDProvenance{
+				Kind: schemas.ProvenancePackage,
+				ID:   leaf.ID,
+			})
+		}
+	}
+
+	// every SBOM that describes or includes a package or an artifact is
+	// another sighting of it
+	for _, hasSBOM := range ids.HasSBOMs {
+		provenance := schemas.GuacIDProvenance{
+			Kind:     schemas.ProvenanceSBOM,
+			ID:       hasSBOM.ID,
+			Origin:   hasSBOM.URI,
+			Document: hasSBOMDocument(hasSBOM),
+		}
+		software := hasSBOM.IncludedSoftware
+		if hasSBOM.Subject != nil {
+			software = append([]model.PackageOrArtifact{hasSBOM.Subject}, software...)
+		}
+		for _, node := range software {
+			switch n := node.(type) {
+			case *model.Package:
+				for _, leaf := range packageLeaves(n) {
+					p.add(p.Aliases.ConvertPurlToGuacID(leaf.Purl), provenance)
+				}
+			case *model.Artifact:
+				p.add(schemas.ConvertArtifactToGuacID(schemas.Artifact{
+					Algorithm: n.Algorithm,
+					Digest:    n.Digest,
+				}), provenance)
+			}
+		}
 	}
-	if guacID.Version != "" {
-		fields = append(fields, guacID.Version)
+
+	p.processLinks(ids)
+}
+
+// add records where guacID came from and returns its digest. A node that
+// already contributed to the GuacID, in this run or a seeded earlier one, is
+// not recorded again, and the GuacID is counted once per document it was
+// seen in.
+func (p *IdentifierProcessor) add(guacID schemas.GuacID, provenance schemas.GuacIDProvenance) string {
+	digest := getGuacIdDigest(guacID)
+
+	key := provenanceKey(digest, provenance)
+	if p.seen[key] {
+		return digest
 	}
-	if guacID.Arch != "" {
-		fields = append(fields, guacID.Arch)
+	p.seen[key] = true
+
+	existing, exists := p.GuacIDs[digest]
+	if !exists {
+		guacID.Digest = digest
+		guacID.DigestVersion = schemas.GuacIDDigestVersion
+		guacID.Count = 0
+		schemas.SetCanonicalStrings(&guacID)
+		existing = guacID
 	}
-	if len(guacID.Other) > 0 {
-		sortedOther := append([]string{}, guacID.Other...)
-		sort.Strings(sortedOther)
-		fields = append(fields, sortedOther...)
+	existing.Provenance = append(existing.Provenance, provenance)
+	if p.documents[digest] == nil {
+		p.documents[digest] = make(map[string]bool)
 	}
-	if guacID.SubPath != "" {
-		fields = append(fields, guacID.SubPath)
+	if document := provenanceDocument(provenance); document != "" {
+		p.documents[digest][document] = true
 	}
-	if guacID.PkgRel != "" {
-		fields = append(fields, guacID.PkgRel)
+	existing.Count = max(int64(len(p.documents[digest])), 1)
+	for _, original := range guacID.Originals {
+		if !slices.Contains(existing.Originals, original) {
+			existing.Originals = append(existing.Originals, original)
+		}
 	}
-	if guacID.Edition != "" {
-		fields = append(fields, guacID.Edition)
+	for _, alias := range guacID.Aliases {
+		if !slices.Contains(existing.Aliases, alias) {
+			existing.Aliases = append(existing.Aliases, alias)
+		}
 	}
+	p.GuacIDs[digest] = existing
 
-	combinedString := strings.Join(fields, "")
-	hash := sha256.Sum256([]byte(combinedString))
-	return fmt.Sprintf("%x", hash) 
+	return digest
 }
-func ProcessIdentifiers(logger *zap.Logger, artifacts []*model.Artifact, hasMetadatas []*model.HasMetadata, packages []*model.Package) ([]schemas.CPE, []schemas.Purl, map[string]schemas.GuacID) {
 
-	
-	GuacIDs := make(map[string]schemas.GuacID)
+func provenanceKey(digest string, provenance schemas.GuacIDProvenance) string {
+	return digest + "|" + provenance.Kind + "|" + provenance.ID
+}
 
-	CPEs := []schemas.CPE{}
-	for _, metadata := range hasMetadatas {
-		if metadata.Key == "cpe" {
-			cpe, err := schemas.ParseCPE(metadata.Value)
-			if err != nil {
-				logger.Info("unable to parse", zap.String(metadata.Key, metadata.Value))
-				continue
-			}
-			CPEs = append(CPEs, cpe)
-			guacID := schemas.ConvertCPEToGuacID(cpe)
+// hasSBOMDocument identifies the document of a HasSBOM by the blob GUAC
+// stored it as, or else by its digest.
+func hasSBOMDocument(hasSBOM *model.HasSbom) string {
+	if hasSBOM.DocumentRef != "" {
+		return hasSBOM.DocumentRef
+	}
+	if hasSBOM.Digest != "" {
+		return hasSBOM.Algorithm + "_" + hasSBOM.Digest
+	}
+	return ""
+}
 
-			digest := getGuacIdDigest(guacID)
-			if existing, exists := GuacIDs[guacID.Digest]; exists {
-				existing.Count++
-				GuacIDs[guacID.Digest] = existing
-			} else {
-				guacID.Count = 1
-				
-				GuacIDs[digest] = guacID
-			}
-		}
+// hasMetadataDocument identifies the document a HasMetadata was ingested from
+// the same way hasSBOMDocument does, so that the HasSBOM and the HasMetadata
+// of one SBOM are the same document.
+func hasMetadataDocument(metadata *model.HasMetadata) string {
+	if metadata.DocumentRef != "" {
+		return metadata.DocumentRef
 	}
+	return metadata.Origin
+}
+
+// provenanceDocument names the document a provenance was read from, so that a
+// CPE, a purl or an artifact is counted once per document whichever nodes it
+// was read from. Package and artifact nodes belong to no document of their
+// own.
+func provenanceDocument(provenance schemas.GuacIDProvenance) string {
+	if provenance.Document != "" {
+		return provenance.Document
+	}
+	switch provenance.Kind {
+	case schemas.ProvenanceHasMetadata, schemas.ProvenanceSBOM:
+		return provenance.Kind + ":" + provenance.ID
+	}
+	return ""
+}
 
-	Purls := []schemas.Purl{}
-	for _, pkg := range packages {
-		basePurl := schemas.Purl{
-			Scheme: "pkg",
-			Type:   pkg.Type,
+// provenanceDocuments returns the documents of every provenance of a GuacID.
+func provenanceDocuments(provenance []schemas.GuacIDProvenance) map[string]bool {
+	documents := make(map[string]bool)
+	for _, p := range provenance {
+		if document := provenanceDocument(p); document != "" {
+			documents[document] = true
 		}
+	}
+	return documents
+}
 
-		if len(pkg.Namespaces) == 0 {
-			Purls = append(Purls, basePurl)
-			guacID := schemas.ConvertPurlToGuacID(basePurl)
+// packageLeaf is one purl of a package tree together with the ID of the node
+// it was read from.
+type packageLeaf struct {
+	ID   string
+	Purl schemas.Purl
+}
 
-			digest := getGuacIdDigest(guacID)
-			if existing, exists := GuacIDs[guacID.Digest]; exists {
-				existing.Count++
-				GuacIDs[guacID.Digest] = existing
-			} else {
-				guacID.Count = 1
-				
-				GuacIDs[digest] = guacID
-			}
+// packageLeaves flattens a package tree into one purl per leaf node, keeping
+// all qualifiers of a version on its purl.
+func packageLeaves(pkg *model.Package) []packageLeaf {
+	leaves := []packageLeaf{}
+
+	basePurl := schemas.Purl{
+		Scheme: "pkg",
+		Type:   pkg.Type,
+	}
+
+	if len(pkg.Namespaces) == 0 {
+		return append(leaves, packageLeaf{ID: pkg.ID, Purl: basePurl})
+	}
+
+	for _, namespace := range pkg.Namespaces {
+		nsPurl := basePurl
+		nsPurl.Namespace = namespace.Namespace
+
+		if len(namespace.Names) == 0 {
+			leaves = append(leaves, packageLeaf{ID: namespace.ID, Purl: nsPurl})
 			continue
 		}
 
-		for _, namespace := range pkg.Namespaces {
-			nsPurl := basePurl
-			nsPurl.Namespace = namespace.Namespace
-
-			if len(namespace.Names) == 0 {
-				Purls = append(Purls, nsPurl)
-				guacID := schemas.ConvertPurlToGuacID(nsPurl)
-
-				digest := getGuacIdDigest(guacID)
-				if existing, exists := GuacIDs[guacID.Digest]; exists {
-					existing.Count++
-					GuacIDs[guacID.Digest] = existing
-				} else {
-					guacID.Count = 1
-					
-					GuacIDs[digest] = guacID
-				}
+		for _, name := range namespace.Names {
+			namePurl := nsPurl
+			namePurl.Name = name.Name
+
+			if len(name.Versions) == 0 {
+				leaves = append(leaves, packageLeaf{ID: name.ID, Purl: namePurl})
 				continue
 			}
 
-			for _, name := range namespace.Names {
-				namePurl := nsPurl
-				namePurl.Name = name.Name
+			for _, version := range name.Versions {
+				versionPurl := namePurl
+				versionPurl.Version = version.Version
+				versionPurl.SubPath = version.Subpath
 
-				if len(name.Versions) == 0 {
-					Purls = append(Purls, namePurl)
-					guacID := schemas.ConvertPurlToGuacID(namePurl)
-
-					digest := getGuacIdDigest(guacID)
-					if existing, exists := GuacIDs[guacID.Digest]; exists {
-						existing.Count++
-						GuacIDs[guacID.Digest] = existing
-					} else {
-						guacID.Count = 1
-						
-						GuacIDs[digest] = guacID
-					}
-					continue
-				}
-
-				for _, version := range name.Versions {
-					versionPurl := namePurl
-					versionPurl.Version = version.Version
-					versionPurl.SubPath = version.Subpath
-
-					if len(version.Qualifiers) == 0 {
-						Purls = append(Purls, versionPurl)
-						guacID := schemas.ConvertPurlToGuacID(versionPurl)
-
-						digest := getGuacIdDigest(guacID)
-						if existing, exists := GuacIDs[guacID.Digest]; exists {
-							existing.Count++
-							GuacIDs[guacID.Digest] = existing
-						} else {
-							guacID.Count = 1
-							
-							GuacIDs[digest] = guacID
-						}
+				// all qualifiers belong to the one version they qualify
+				for _, qualifier := range version.Qualifiers {
+					if qualifier.Value == "" {
 						continue
 					}
-
-					for _, qualifier := range version.Qualifiers {
-						qualifierPurl := versionPurl
-						// Compute qualarch and qualx here
-						switch qualifier.Key {
-						case "arch":
-							qualifierPurl.QualArch = qualifier.Value
-						default:
-							qualifierPurl.QualX = qualifier.Key + "|" + qualifier.Value
-						}
-						Purls = append(Purls, qualifierPurl)
-						guacID := schemas.ConvertPurlToGuacID(qualifierPurl)
-
-						digest := getGuacIdDigest(guacID)
-						
-						if existing, exists := GuacIDs[guacID.Digest]; exists {
-							existing.Count++
-							GuacIDs[guacID.Digest] = existing
-						} else {
-							guacID.Count = 1
-							
-							GuacIDs[digest] = guacID
-						}
+					if versionPurl.Qualifiers == nil {
+						versionPurl.Qualifiers = make(map[string]string)
 					}
+					versionPurl.Qualifiers[qualifier.Key] = qualifier.Value
 				}
+				leaves = append(leaves, packageLeaf{ID: version.ID, Purl: versionPurl})
 			}
 		}
 	}
 
-	return CPEs, Purls, GuacIDs
+	return leaves
+
+}
+
+// vertexHardness marks a vertex hard when its value identifies exactly one
+// thing, such as a content hash or a UUID.
+func vertexHardness(value string) schemas.NodeHardness {
+	if helpers.ClassifyIdentifier(value).IsHard() {
+		return schemas.NodeHardnessHard
+	}
+	return schemas.NodeHardnessSoft
 }
 
 func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Graph[string, *schemas.GuacIDNode], error) {
 	guacIdGraph := graph.New(schemas.GuacIDNodeID, graph.Directed())
 	for _, gID := range GuacIDs {
+		if gID.Kind == schemas.GuacIDKindArtifact || gID.Kind == schemas.GuacIDKindGitoid || gID.Kind == schemas.GuacIDKindSWID {
+			// artifacts and persistent IDs have no attributes, the one value
+			// they carry is the identifier
+			vertex := guacIDVertex(gID)
+			err := guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: vertex, NodeType: schemas.NodeHardnessHard})
+			if err != nil && err != graph.ErrVertexAlreadyExists {
+				logger.Error(err.Error(), zap.String("Vertex", vertex))
+			}
+			continue
+		}
+
 		if gID.Name != "" {
 			_, err := guacIdGraph.Vertex(gID.Name)
 			if err != nil && err != graph.ErrVertexAlreadyExists {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.Name)
 
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Name|" + gID.Name, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
@@ -260,10 +464,7 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 			// add arch
 			_, err := guacIdGraph.Vertex(gID.Arch)
 			if err != nil {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.Arch)
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Arch|" + gID.Arch, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
 					logger.Error(err.Error(), zap.String("Arch", gID.Arch))
@@ -287,10 +488,7 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 			//add ecosystem
 			_, err := guacIdGraph.Vertex(gID.Ecosystem)
 			if err != nil {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.Ecosystem)
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Ecosystem|" + gID.Ecosystem, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
 					logger.Error(err.Error(), zap.String("Ecosystem", gID.Ecosystem))
@@ -314,10 +512,7 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 			// add edition
 			_, err := guacIdGraph.Vertex(gID.Edition)
 			if err != nil {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.Edition)
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Edition|" + gID.Edition, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
 					logger.Error(err.Error(), zap.String("Edition", gID.Edition))
@@ -340,10 +535,7 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 			// add subpath
 			_, err := guacIdGraph.Vertex(gID.SubPath)
 			if err != nil {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.SubPath)
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "SubPath|" + gID.SubPath, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
 					logger.Error(err.Error(), zap.String("SubPath", gID.SubPath))
@@ -364,10 +556,7 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 		if gID.Version != "" {
 			_, err := guacIdGraph.Vertex(gID.Version)
 			if err != nil {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.Version)
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Version|" + gID.Version, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
 					logger.Error(err.Error(), zap.String("Version", gID.Version))
@@ -385,14 +574,57 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 			}
 		}
 
+		if gID.Epoch != "" {
+			_, err := guacIdGraph.Vertex("Epoch|" + gID.Epoch)
+			if err != nil {
+				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Epoch|" + gID.Epoch, NodeType: schemas.NodeHardnessSoft})
+				if err != nil && err != graph.ErrVertexAlreadyExists {
+					logger.Error(err.Error(), zap.String("Epoch", gID.Epoch))
+				}
+			}
+
+			_, err = guacIdGraph.Edge("Epoch|"+gID.Epoch, "Name|"+gID.Name)
+			if err != nil {
+				err = guacIdGraph.AddEdge("Epoch|"+gID.Epoch, "Name|"+gID.Name, graph.EdgeData(schemas.GuacIDEdge{}))
+				if err != nil && err != graph.ErrEdgeAlreadyExists {
+					logger.Error(err.Error(), zap.String("Source", "Epoch|"+gID.Epoch), zap.String("Target", "Name|"+gID.Name))
+				}
+			}
+		}
+
+		// part is left out: with three values it would join nearly everything
+		cpeAttributes := []struct{ label, value string }{
+			{"TargetSW", gID.TargetSW},
+			{"SWEdition", gID.SWEdition},
+			{"Language", gID.Language},
+		}
+		for _, attribute := range cpeAttributes {
+			if attribute.value == "" {
+				continue
+			}
+			vertex := attribute.label + "|" + attribute.value
+			_, err := guacIdGraph.Vertex(vertex)
+			if err != nil {
+				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: vertex, NodeType: vertexHardness(attribute.value)})
+				if err != nil && err != graph.ErrVertexAlreadyExists {
+					logger.Error(err.Error(), zap.String(attribute.label, attribute.value))
+				}
+			}
+
+			_, err = guacIdGraph.Edge(vertex, "Name|"+gID.Name)
+			if err != nil {
+				err = guacIdGraph.AddEdge(vertex, "Name|"+gID.Name, graph.EdgeData(schemas.GuacIDEdge{}))
+				if err != nil && err != graph.ErrEdgeAlreadyExists {
+					logger.Error(err.Error(), zap.String("Source", vertex), zap.String("Target", "Name|"+gID.Name))
+				}
+			}
+		}
+
 		if gID.PkgRel != "" {
 
 			_, err := guacIdGraph.Vertex(gID.PkgRel)
 			if err != nil {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.PkgRel)
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "PkgRel|" + gID.PkgRel, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
 					logger.Error(err.Error(), zap.String("PkgRel", gID.PkgRel))
@@ -414,10 +646,7 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 
 			_, err := guacIdGraph.Vertex(gID.Namespace)
 			if err != nil {
-				nodeType := schemas.NodeHardnessSoft
-				if helpers.IsSHAOrUUID(gID.Name) {
-					nodeType = schemas.NodeHardnessHard
-				}
+				nodeType := vertexHardness(gID.Namespace)
 				err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Namespace|" + gID.Namespace, NodeType: nodeType})
 				if err != nil && err != graph.ErrVertexAlreadyExists {
 					logger.Error(err.Error(), zap.String("Namespace", gID.Namespace))
@@ -435,14 +664,33 @@ func CreateGuacIDGraph(logger *zap.Logger, GuacIDs []schemas.GuacID) (graph.Grap
 			}
 		}
 
+		if len(gID.Qualifiers) != 0 {
+			for key, value := range gID.Qualifiers {
+				qualifier := key + "=" + value
+				_, err := guacIdGraph.Vertex("Qualifier|" + qualifier)
+				if err != nil {
+					nodeType := vertexHardness(value)
+					err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Qualifier|" + qualifier, NodeType: nodeType})
+					if err != nil && err != graph.ErrVertexAlreadyExists {
+						logger.Error(err.Error(), zap.String("Qualifier", qualifier))
+					}
+				}
+
+				_, err = guacIdGraph.Edge("Qualifier|"+qualifier, "Name|"+gID.Name)
+				if err != nil {
+					err = guacIdGraph.AddEdge("Qualifier|"+qualifier, "Name|"+gID.Name, graph.EdgeData(schemas.GuacIDEdge{}))
+					if err != nil && err != graph.ErrEdgeAlreadyExists {
+						logger.Error(err.Error(), zap.String("Source", "Qualifier|"+qualifier), zap.String("Target", "Name|"+gID.Name))
+					}
+				}
+			}
+		}
+
 		if len(gID.Other) != 0 {
 			for _, other := range gID.Other {
 				_, err := guacIdGraph.Vertex(other)
 				if err != nil {
-					nodeType := schemas.NodeHardnessSoft
-					if helpers.IsSHAOrUUID(gID.Name) {
-						nodeType = schemas.NodeHardnessHard
-					}
+					nodeType := vertexHardness(other)
 					err = guacIdGraph.AddVertex(&schemas.GuacIDNode{NodeID: "Other|" + other, NodeType: nodeType})
 					if err != nil && err != graph.ErrVertexAlreadyExists {
 						logger.Error(err.Error(), zap.String("Other", other))
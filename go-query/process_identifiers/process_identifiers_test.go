@@ -0,0 +1,93 @@
+package processidentifiers
+
+import (
+	"testing"
+
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+	"go.uber.org/zap"
+
+	"go-query/schemas"
+)
+
+// TestGuacIDGraphHardness checks that every attribute vertex is classified by
+// its own value, not by the name of the GuacID it came from.
+func TestGuacIDGraphHardness(t *testing.T) {
+	guacIdGraph, err := CreateGuacIDGraph(zap.NewNop(), []schemas.GuacID{
+		{Kind: schemas.GuacIDKindPurl, Ecosystem: "golang", Namespace: "github.com/google", Name: "uuid", Version: "v0.0.0-20190827235216-6bf3a9d5f8c0"},
+		{Kind: schemas.GuacIDKindPurl, Ecosystem: "github", Namespace: "package-url", Name: "purl-spec", Version: "244fd47e07d1004f0aed9c3ba9b2a4a8b6c7d8e9"},
+		{Kind: schemas.GuacIDKindPurl, Ecosystem: "generic", Name: "da39a3ee5e6b4b0d3255bfef95601890afd80709", Version: "1.0.0"},
+		{Kind: schemas.GuacIDKindArtifact, Algorithm: "sha256", Digest: "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		vertex string
+		want   schemas.NodeHardness
+	}{
+		{"Name|uuid", schemas.NodeHardnessSoft},
+		{"Ecosystem|golang", schemas.NodeHardnessSoft},
+		{"Version|v0.0.0-20190827235216-6bf3a9d5f8c0", schemas.NodeHardnessSoft},
+		// a commit hash version is hard under a soft name
+		{"Name|purl-spec", schemas.NodeHardnessSoft},
+		{"Version|244fd47e07d1004f0aed9c3ba9b2a4a8b6c7d8e9", schemas.NodeHardnessHard},
+		{"Name|da39a3ee5e6b4b0d3255bfef95601890afd80709", schemas.NodeHardnessHard},
+		// the version of a GuacID with a hard name is still soft
+		{"Version|1.0.0", schemas.NodeHardnessSoft},
+		{"Digest|sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", schemas.NodeHardnessHard},
+	}
+
+	for _, tt := range tests {
+		node, err := guacIdGraph.Vertex(tt.vertex)
+		if err != nil {
+			t.Errorf("vertex %s: %s", tt.vertex, err)
+			continue
+		}
+		if node.NodeType != tt.want {
+			t.Errorf("vertex %s has hardness %d; want %d", tt.vertex, node.NodeType, tt.want)
+		}
+	}
+}
+
+// TestCountByDocument checks that a GuacID is counted once per document it was
+// seen in, however many nodes of the document name it and however often they
+// are processed.
+func TestCountByDocument(t *testing.T) {
+	pkg := &model.Package{ID: "1", Type: "npm", Namespaces: []*model.PackageNamespace{{ID: "2", Names: []*model.PackageName{{ID: "3", Name: "left-pad", Versions: []*model.PackageVersion{{ID: "4", Version: "1.3.0"}}}}}}}
+	ids := &Identifiers{
+		Packages: []*model.Package{pkg},
+		HasSBOMs: []*model.HasSbom{
+			// the subject of an SBOM is often among its packages too
+			{ID: "5", Subject: pkg, DocumentRef: "doc-a", IncludedSoftware: []model.PackageOrArtifact{pkg}},
+			// without a document reference the digest names the document
+			{ID: "6", Algorithm: "sha256", Digest: "bbb", IncludedSoftware: []model.PackageOrArtifact{pkg}},
+		},
+		HasMetadatas: []*model.HasMetadata{
+			{ID: "7", Key: "purl", Value: "pkg:npm/left-pad@1.3.0", DocumentRef: "doc-a"},
+		},
+	}
+
+	processor := NewIdentifierProcessor(zap.NewNop())
+	processor.Process(ids)
+	processor.Process(ids)
+	digest := findDigest(processor.GuacIDs, "left-pad")
+	if count := processor.GuacIDs[digest].Count; count != 2 {
+		t.Errorf("Count = %d; want 2, one for each document", count)
+	}
+
+	// a later run seeded from this one does not count the documents again
+	seeded := NewIdentifierProcessor(zap.NewNop())
+	seeded.Seed(processor.GuacIDs, processor.Links(), nil)
+	seeded.Process(ids)
+	if count := seeded.GuacIDs[digest].Count; count != 2 {
+		t.Errorf("Count after a seeded run = %d; want 2", count)
+	}
+
+	// a package node alone belongs to no document and counts once
+	alone := NewIdentifierProcessor(zap.NewNop())
+	alone.Process(&Identifiers{Packages: []*model.Package{pkg}})
+	if count := alone.GuacIDs[digest].Count; count != 1 {
+		t.Errorf("Count of a package node alone = %d; want 1", count)
+	}
+}
@@ -0,0 +1,393 @@
+package processidentifiers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-query/schemas"
+
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+	"go.uber.org/zap"
+)
+
+// sbomIdentifiers accumulates the nodes found across a directory of SBOMs,
+// keeping each node once by ID the same way the backend queries do.
+type sbomIdentifiers struct {
+	Identifiers
+
+	seen map[string]bool
+}
+
+// GetAllIdentifiersFromSBOMs reads every JSON file under dir and returns the
+// same identifiers GetAllIdentifiers returns from a backend. GUAC HasSBOM
+// exports, SPDX JSON and CycloneDX JSON are supported.
+func GetAllIdentifiersFromSBOMs(logger *zap.Logger, dir string) (*Identifiers, error) {
+
+	ids := &sbomIdentifiers{seen: make(map[string]bool)}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read sbom %s: %s", path, err)
+		}
+
+		if err := ids.addDocument(path, data); err != nil {
+			logger.Info("unable to parse sbom", zap.String("path", path), zap.Error(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to walk sbom directory %s", err)
+	}
+
+	return &ids.Identifiers, nil
+}
+
+func (ids *sbomIdentifiers) addDocument(path string, data []byte) error {
+	var probe struct {
+		SPDXVersion      string          `json:"spdxVersion"`
+		BOMFormat        string          `json:"bomFormat"`
+		IncludedSoftware json.RawMessage `json:"includedSoftware"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+
+	switch {
+	case probe.IncludedSoftware != nil:
+		var doc jsonHasSBOM
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		hasSBOM, err := doc.toModel()
+		if err != nil {
+			return err
+		}
+		ids.addHasSBOM(hasSBOM)
+		return nil
+	case probe.SPDXVersion != "":
+		var doc spdxDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		ids.addSPDX(path, doc, newDocumentSBOM(path, doc.DocumentNamespace, data))
+		return nil
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		var doc cdxDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		ids.addCycloneDX(path, doc, newDocumentSBOM(path, doc.SerialNumber, data))
+		return nil
+	}
+	return fmt.Errorf("unrecognized sbom format")
+}
+
+func (ids *sbomIdentifiers) addHasSBOM(hasSBOM *model.HasSbom) {
+	if ids.seen[hasSBOM.ID] {
+		return
+	}
+	ids.seen[hasSBOM.ID] = true
+	ids.HasSBOMs = append(ids.HasSBOMs, hasSBOM)
+
+	software := append([]model.PackageOrArtifact{hasSBOM.Subject}, hasSBOM.IncludedSoftware...)
+	for _, occurrence := range hasSBOM.IncludedOccurrences {
+		if pkg, ok := occurrence.Subject.(*model.Package); ok {
+			software = append(software, pkg)
+		}
+		if occurrence.Artifact != nil {
+			ids.addArtifact(occurrence.Artifact)
+		}
+		ids.addOccurrence(occurrence)
+	}
+
+	for _, node := range software {
+		switch n := node.(type) {
+		case *model.Package:
+			ids.addPackage(n)
+		case *model.Artifact:
+			ids.addArtifact(n)
+		}
+	}
+}
+
+// newDocumentSBOM returns the HasSBOM GUAC would ingest an SPDX or CycloneDX
+// document as, for the document to be counted once like a GUAC HasSBOM.
+func newDocumentSBOM(path, uri string, data []byte) *model.HasSbom {
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	return &model.HasSbom{
+		ID:          "has_sbom:" + path,
+		URI:         uri,
+		Algorithm:   "sha256",
+		Digest:      digest,
+		Origin:      "file://" + path,
+		Collector:   "FileCollector",
+		DocumentRef: "sha256_" + digest,
+	}
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxDocument struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DocumentNamespace string `json:"documentNamespace"`
+	Packages          []struct {
+		SPDXID       string         `json:"SPDXID"`
+		Name         string         `json:"name"`
+		Checksums    []spdxChecksum `json:"checksums"`
+		ExternalRefs []struct {
+			ReferenceCategory string `json:"referenceCategory"`
+			ReferenceType     string `json:"referenceType"`
+			ReferenceLocator  string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+	Files []struct {
+		SPDXID    string         `json:"SPDXID"`
+		Checksums []spdxChecksum `json:"checksums"`
+	} `json:"files"`
+}
+
+func (ids *sbomIdentifiers) addSPDX(path string, doc spdxDocument, hasSBOM *model.HasSbom) {
+	for _, spdxPkg := range doc.Packages {
+		var pkg *model.Package
+		var metadata [][2]string
+
+		for _, ref := range spdxPkg.ExternalRefs {
+			switch ref.ReferenceType {
+			case "purl":
+				if p := purlToPackage(ref.ReferenceLocator); p != nil {
+					ids.addPackage(p)
+					hasSBOM.IncludedSoftware = append(hasSBOM.IncludedSoftware, p)
+					pkg = p
+				}
+			case "cpe23Type", "cpe22Type":
+				metadata = append(metadata, [2]string{"cpe", ref.ReferenceLocator})
+			case "gitoid", "swid":
+				metadata = append(metadata, [2]string{ref.ReferenceType, ref.ReferenceLocator})
+			}
+		}
+
+		for _, m := range metadata {
+			ids.addMetadata(hasSBOM, doc.DocumentNamespace+"#"+spdxPkg.SPDXID, m[0], m[1], pkg, m[0]+" from spdx package externalRef")
+		}
+		for _, checksum := range spdxPkg.Checksums {
+			artifact := ids.addArtifact(&model.Artifact{Algorithm: checksum.Algorithm, Digest: checksum.ChecksumValue})
+			hasSBOM.IncludedSoftware = append(hasSBOM.IncludedSoftware, artifact)
+			if pkg != nil {
+				ids.addPackageOccurrence(path, pkg, artifact, "spdx package with checksum")
+			}
+		}
+	}
+
+	for _, file := range doc.Files {
+		for _, checksum := range file.Checksums {
+			artifact := ids.addArtifact(&model.Artifact{Algorithm: checksum.Algorithm, Digest: checksum.ChecksumValue})
+			hasSBOM.IncludedSoftware = append(hasSBOM.IncludedSoftware, artifact)
+		}
+	}
+	ids.HasSBOMs = append(ids.HasSBOMs, hasSBOM)
+}
+
+type cdxComponent struct {
+	BOMRef string `json:"bom-ref"`
+	Purl   string `json:"purl"`
+	CPE    string `json:"cpe"`
+	SWID   *struct {
+		TagID string `json:"tagId"`
+	} `json:"swid"`
+	OmniborIDs []string `json:"omniborId"`
+	Hashes     []struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	} `json:"hashes"`
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxDocument struct {
+	BOMFormat    string `json:"bomFormat"`
+	SerialNumber string `json:"serialNumber"`
+	Metadata     struct {
+		Component *cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components []cdxComponent `json:"components"`
+}
+
+func (ids *sbomIdentifiers) addCycloneDX(path string, doc cdxDocument, hasSBOM *model.HasSbom) {
+	components := doc.Components
+	if doc.Metadata.Component != nil {
+		components = append([]cdxComponent{*doc.Metadata.Component}, components...)
+		if subject := purlToPackage(doc.Metadata.Component.Purl); subject != nil {
+			hasSBOM.Subject = subject
+		}
+	}
+
+	var walk func(components []cdxComponent)
+	walk = func(components []cdxComponent) {
+		for _, component := range components {
+			var pkg *model.Package
+			if component.Purl != "" {
+				if p := purlToPackage(component.Purl); p != nil {
+					ids.addPackage(p)
+					hasSBOM.IncludedSoftware = append(hasSBOM.IncludedSoftware, p)
+					pkg = p
+				}
+			}
+			ref := doc.SerialNumber + "#" + component.BOMRef
+			if component.CPE != "" {
+				ids.addMetadata(hasSBOM, ref, "cpe", component.CPE, pkg, "cpe from cdx component")
+			}
+			if component.SWID != nil && component.SWID.TagID != "" {
+				ids.addMetadata(hasSBOM, ref, "swid", component.SWID.TagID, pkg, "swid from cdx component")
+			}
+			for _, omniborID := range component.OmniborIDs {
+				ids.addMetadata(hasSBOM, ref, "gitoid", omniborID, pkg, "omniborId from cdx component")
+			}
+			for _, hash := range component.Hashes {
+				artifact := ids.addArtifact(&model.Artifact{Algorithm: hash.Alg, Digest: hash.Content})
+				hasSBOM.IncludedSoftware = append(hasSBOM.IncludedSoftware, artifact)
+				if pkg != nil {
+					ids.addPackageOccurrence(path, pkg, artifact, "cdx package with checksum")
+				}
+			}
+			walk(component.Components)
+		}
+	}
+	walk(components)
+	ids.HasSBOMs = append(ids.HasSBOMs, hasSBOM)
+}
+
+// addMetadata records an identifier an SBOM attaches to a component as the
+// HasMetadata node GUAC would ingest it as.
+func (ids *sbomIdentifiers) addMetadata(hasSBOM *model.HasSbom, ref, key, value string, subject *model.Package, justification string) {
+	id := "has_metadata:" + ref + "|" + value
+	if ids.seen[id] {
+		return
+	}
+	ids.seen[id] = true
+
+	hasMetadata := &model.HasMetadata{
+		ID:            id,
+		Key:           key,
+		Value:         value,
+		Justification: justification,
+		Origin:        hasSBOM.Origin,
+		Collector:     hasSBOM.Collector,
+		DocumentRef:   hasSBOM.DocumentRef,
+	}
+	if subject != nil {
+		hasMetadata.Subject = subject
+	}
+	ids.HasMetadatas = append(ids.HasMetadatas, hasMetadata)
+}
+
+func (ids *sbomIdentifiers) addPackageOccurrence(path string, pkg *model.Package, artifact *model.Artifact, justification string) {
+	ids.addOccurrence(&model.IsOccurrence{
+		ID:            "occurrences:" + pkg.Namespaces[0].Names[0].Versions[0].Purl + "|" + artifact.ID,
+		Subject:       pkg,
+		Artifact:      artifact,
+		Justification: justification,
+		Origin:        "file://" + path,
+		Collector:     "FileCollector",
+	})
+}
+
+func (ids *sbomIdentifiers) addOccurrence(occurrence *model.IsOccurrence) {
+	if ids.seen[occurrence.ID] {
+		return
+	}
+	ids.seen[occurrence.ID] = true
+	ids.Occurrences = append(ids.Occurrences, occurrence)
+}
+
+// addArtifact returns the artifact with its algorithm, digest and ID filled in.
+func (ids *sbomIdentifiers) addArtifact(artifact *model.Artifact) *model.Artifact {
+	artifact.Algorithm = schemas.NormalizeDigestAlgorithm(artifact.Algorithm)
+	artifact.Digest = strings.ToLower(artifact.Digest)
+	if artifact.ID == "" {
+		artifact.ID = "artifacts:" + artifact.Algorithm + ":" + artifact.Digest
+	}
+	if ids.seen[artifact.ID] {
+		return artifact
+	}
+	ids.seen[artifact.ID] = true
+	ids.Artifacts = append(ids.Artifacts, artifact)
+	return artifact
+}
+
+// addPackage keeps a package tree once per leaf node, so a package version
+// listed by many SBOMs is only returned once. The SBOMs listing it are counted
+// through their HasSBOMs.
+func (ids *sbomIdentifiers) addPackage(pkg *model.Package) {
+	id := pkg.ID
+	for _, namespace := range pkg.Namespaces {
+		id = namespace.ID
+		for _, name := range namespace.Names {
+			id = name.ID
+			for _, version := range name.Versions {
+				id = version.ID
+			}
+		}
+	}
+	if ids.seen[id] {
+		return
+	}
+	ids.seen[id] = true
+	ids.Packages = append(ids.Packages, pkg)
+}
+
+// purlToPackage turns a purl into a single path package tree, identified by
+// its canonical form so the same package spelled differently is kept once.
+func purlToPackage(purlStr string) *model.Package {
+	purl, err := schemas.ParsePurl(purlStr)
+	if err != nil {
+		return nil
+	}
+	canonical := purl.String()
+
+	qualifiers := []*model.PackageQualifier{}
+	for key, value := range purl.Qualifiers {
+		qualifiers = append(qualifiers, &model.PackageQualifier{Key: key, Value: value})
+	}
+	sort.Slice(qualifiers, func(i, j int) bool {
+		return qualifiers[i].Key < qualifiers[j].Key
+	})
+
+	namespaceID := purl.Type + "guac-split-@@" + purl.Namespace
+	return &model.Package{
+		ID:   "package_types:" + purl.Type,
+		Type: purl.Type,
+		Namespaces: []*model.PackageNamespace{{
+			ID:        "package_namespaces:" + namespaceID,
+			Namespace: purl.Namespace,
+			Names: []*model.PackageName{{
+				ID:   "package_names:" + namespaceID + "/" + purl.Name,
+				Name: purl.Name,
+				Versions: []*model.PackageVersion{{
+					ID:         "package_versions:" + canonical,
+					Purl:       canonical,
+					Version:    purl.Version,
+					Qualifiers: qualifiers,
+					Subpath:    purl.SubPath,
+				}},
+			}},
+		}},
+	}
+}
@@ -0,0 +1,195 @@
+package processidentifiers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guacsec/guac/pkg/assembler/graphql/model"
+)
+
+// StreamProgress reports how many nodes of one kind have been fetched so far
+// out of the total the backend announced.
+type StreamProgress struct {
+	Kind    string
+	Fetched int
+	Total   int
+}
+
+type ProgressFunc func(StreamProgress)
+
+// PagedIdentifierSource is an IdentifierSource that can hand out its nodes a
+// page at a time. Each page is passed to handle as an Identifiers holding a
+// single kind of node.
+type PagedIdentifierSource interface {
+	IdentifierSource
+	IdentifierPages(ctx context.Context, pageSize int, progress ProgressFunc, handle func(*Identifiers) error) error
+}
+
+// StreamIdentifiers feeds every node of src through processor. Paged sources
+// are read a page at a time so only one page of nodes is held in memory,
+// though what the processor keeps of them still grows with the source; any
+// other source is read whole and processed in one batch.
+func StreamIdentifiers(ctx context.Context, src IdentifierSource, pageSize int, processor *IdentifierProcessor, progress ProgressFunc) error {
+	paged, ok := src.(PagedIdentifierSource)
+	if !ok {
+		ids, err := GetAllIdentifiers(ctx, src)
+		if err != nil {
+			return err
+		}
+		processor.Process(ids)
+		return nil
+	}
+
+	return paged.IdentifierPages(ctx, pageSize, progress, func(page *Identifiers) error {
+		processor.Process(page)
+		return nil
+	})
+}
+
+func (s *BackendSource) IdentifierPages(ctx context.Context, pageSize int, progress ProgressFunc, handle func(*Identifiers) error) error {
+	err := fetchPages(ctx, "artifacts", pageSize, progress,
+		func(after *string, first *int) ([]*model.Artifact, int, *model.PageInfo, error) {
+			conn, err := s.Backend.ArtifactsList(ctx, model.ArtifactSpec{}, after, first)
+			if err != nil || conn == nil {
+				return nil, 0, nil, err
+			}
+			nodes := []*model.Artifact{}
+			for _, edge := range conn.Edges {
+				nodes = append(nodes, edge.Node)
+			}
+			return nodes, conn.TotalCount, conn.PageInfo, nil
+		},
+		func(nodes []*model.Artifact) error {
+			return handle(&Identifiers{Artifacts: nodes})
+		})
+	if err != nil {
+		return err
+	}
+
+	err = fetchPages(ctx, "hasMetadata", pageSize, progress,
+		func(after *string, first *int) ([]*model.HasMetadata, int, *model.PageInfo, error) {
+			conn, err := s.Backend.HasMetadataList(ctx, model.HasMetadataSpec{Since: s.HasMetadataSince}, after, first)
+			if err != nil || conn == nil {
+				return nil, 0, nil, err
+			}
+			nodes := []*model.HasMetadata{}
+			for _, edge := range conn.Edges {
+				nodes = append(nodes, edge.Node)
+			}
+			return nodes, conn.TotalCount, conn.PageInfo, nil
+		},
+		func(nodes []*model.HasMetadata) error {
+			return handle(&Identifiers{HasMetadatas: nodes})
+		})
+	if err != nil {
+		return err
+	}
+
+	err = fetchPages(ctx, "packages", pageSize, progress,
+		func(after *string, first *int) ([]*model.Package, int, *model.PageInfo, error) {
+			conn, err := s.Backend.PackagesList(ctx, model.PkgSpec{}, after, first)
+			if err != nil || conn == nil {
+				return nil, 0, nil, err
+			}
+			nodes := []*model.Package{}
+			for _, edge := range conn.Edges {
+				nodes = append(nodes, edge.Node)
+			}
+			return nodes, conn.TotalCount, conn.PageInfo, nil
+		},
+		func(nodes []*model.Package) error {
+			return handle(&Identifiers{Packages: nodes})
+		})
+	if err != nil {
+		return err
+	}
+
+	err = fetchPages(ctx, "isOccurrence", pageSize, progress,
+		func(after *string, first *int) ([]*model.IsOccurrence, int, *model.PageInfo, error) {
+			conn, err := s.Backend.IsOccurrenceList(ctx, model.IsOccurrenceSpec{}, after, first)
+			if err != nil || conn == nil {
+				return nil, 0, nil, err
+			}
+			nodes := []*model.IsOccurrence{}
+			for _, edge := range conn.Edges {
+				nodes = append(nodes, edge.Node)
+			}
+			return nodes, conn.TotalCount, conn.PageInfo, nil
+		},
+		func(nodes []*model.IsOccurrence) error {
+			return handle(&Identifiers{Occurrences: nodes})
+		})
+	if err != nil {
+		return err
+	}
+
+	err = fetchPages(ctx, "hasSBOM", pageSize, progress,
+		func(after *string, first *int) ([]*model.HasSbom, int, *model.PageInfo, error) {
+			conn, err := s.Backend.HasSBOMList(ctx, model.HasSBOMSpec{}, after, first)
+			if err != nil || conn == nil {
+				return nil, 0, nil, err
+			}
+			nodes := []*model.HasSbom{}
+			for _, edge := range conn.Edges {
+				nodes = append(nodes, edge.Node)
+			}
+			return nodes, conn.TotalCount, conn.PageInfo, nil
+		},
+		func(nodes []*model.HasSbom) error {
+			return handle(&Identifiers{HasSBOMs: nodes})
+		})
+	if err != nil {
+		return err
+	}
+
+	return fetchPages(ctx, "hasSLSA", pageSize, progress,
+		func(after *string, first *int) ([]*model.HasSlsa, int, *model.PageInfo, error) {
+			conn, err := s.Backend.HasSLSAList(ctx, model.HasSLSASpec{}, after, first)
+			if err != nil || conn == nil {
+				return nil, 0, nil, err
+			}
+			nodes := []*model.HasSlsa{}
+			for _, edge := range conn.Edges {
+				nodes = append(nodes, edge.Node)
+			}
+			return nodes, conn.TotalCount, conn.PageInfo, nil
+		},
+		func(nodes []*model.HasSlsa) error {
+			return handle(&Identifiers{HasSLSAs: nodes})
+		})
+}
+
+// fetchPages walks a *List cursor API until the backend reports no next page.
+func fetchPages[N any](ctx context.Context, kind string, pageSize int, progress ProgressFunc,
+	fetch func(after *string, first *int) ([]N, int, *model.PageInfo, error), handle func([]N) error) error {
+
+	var after *string
+	fetched := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		first := pageSize
+		nodes, total, pageInfo, err := fetch(after, &first)
+		if err != nil {
+			return fmt.Errorf("unable to get %s page %s", kind, err)
+		}
+
+		if len(nodes) > 0 {
+			if err := handle(nodes); err != nil {
+				return err
+			}
+		}
+
+		fetched += len(nodes)
+		if progress != nil {
+			progress(StreamProgress{Kind: kind, Fetched: fetched, Total: total})
+		}
+
+		if pageInfo == nil || !pageInfo.HasNextPage || pageInfo.EndCursor == nil {
+			return nil
+		}
+		after = pageInfo.EndCursor
+	}
+}
@@ -0,0 +1,80 @@
+package processidentifiers
+
+import (
+	"errors"
+	"sort"
+
+	"go-query/schemas"
+	"go-query/versions"
+
+	"go.uber.org/zap"
+)
+
+// VersionLinks links every versioned package GuacID to the GuacIDs of the
+// next newer version of the same package, built for the same arch and subpath.
+// GuacIDs whose ecosystem has no version scheme, or whose version the scheme
+// cannot parse, are left out.
+func VersionLinks(logger *zap.Logger, GuacIDs map[string]schemas.GuacID) []schemas.GuacIDLink {
+	packages := make(map[string][]string)
+	for digest, gID := range GuacIDs {
+		if gID.Kind != schemas.GuacIDKindPurl || gID.Version == "" {
+			continue
+		}
+		key := gID.Ecosystem + "|" + gID.Namespace + "|" + gID.Name + "|" + gID.Arch + "|" + gID.SubPath
+		packages[key] = append(packages[key], digest)
+	}
+
+	keys := make([]string, 0, len(packages))
+	for key := range packages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	links := []schemas.GuacIDLink{}
+	for _, key := range keys {
+		digests := packages[key]
+		if len(digests) < 2 {
+			continue
+		}
+
+		scheme, err := versions.ForEcosystem(GuacIDs[digests[0]].Ecosystem)
+		if errors.Is(err, versions.ErrUnknownEcosystem) {
+			continue
+		}
+
+		valid := []string{}
+		for _, digest := range digests {
+			version := versions.GuacIDVersion(GuacIDs[digest])
+			if _, err := scheme.Compare(version, version); err != nil {
+				logger.Debug("skipping unparsable version", zap.String("digest", digest), zap.String("version", version), zap.Error(err))
+				continue
+			}
+			valid = append(valid, digest)
+		}
+
+		compare := func(a, b string) int {
+			c, _ := scheme.Compare(versions.GuacIDVersion(GuacIDs[a]), versions.GuacIDVersion(GuacIDs[b]))
+			return c
+		}
+		sort.Slice(valid, func(i, j int) bool {
+			if c := compare(valid[i], valid[j]); c != 0 {
+				return c < 0
+			}
+			return valid[i] < valid[j]
+		})
+
+		// GuacIDs that differ only in qualifiers share a version, so link
+		// each group of equal versions to the whole next group
+		var previous, current []string
+		for i, digest := range valid {
+			if i > 0 && compare(valid[i-1], digest) != 0 {
+				previous, current = current, nil
+			}
+			current = append(current, digest)
+			for _, older := range previous {
+				links = append(links, schemas.GuacIDLink{Source: older, Target: digest, Kind: schemas.GuacIDLinkNextVersion, Count: 1})
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,178 @@
+package schemas
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AliasEntity is one canonical vendor or product together with the spellings
+// it goes by. Vendors are CPE vendors and Namespaces purl namespaces, both
+// resolved into Namespace; Products are CPE products and Names purl names,
+// both resolved into Name. An alias ending in * matches every value starting
+// with the rest of it, as in org.apache.*.
+type AliasEntity struct {
+	Canonical  string   `json:"canonical" yaml:"canonical"`
+	Vendors    []string `json:"vendors,omitempty" yaml:"vendors,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	Products   []string `json:"products,omitempty" yaml:"products,omitempty"`
+	Names      []string `json:"names,omitempty" yaml:"names,omitempty"`
+	// Ecosystems limits the purl aliases to these purl types. CPE aliases
+	// always apply.
+	Ecosystems []string `json:"ecosystems,omitempty" yaml:"ecosystems,omitempty"`
+}
+
+// Alias fields, as they appear in the aliases recorded on a GuacID.
+const (
+	AliasVendor    = "vendor"
+	AliasNamespace = "namespace"
+	AliasProduct   = "product"
+	AliasName      = "name"
+)
+
+// AliasDictionary resolves vendors, products, namespaces and names onto the
+// canonical entities of a curated list. Aliases are compared ignoring case.
+// A nil dictionary resolves nothing.
+type AliasDictionary struct {
+	Entities []AliasEntity `json:"entities" yaml:"entities"`
+
+	exact    map[string][]aliasTarget
+	prefixes map[string][]aliasPrefix
+}
+
+type aliasTarget struct {
+	canonical  string
+	ecosystems []string
+}
+
+type aliasPrefix struct {
+	prefix string
+	aliasTarget
+}
+
+// NewAliasDictionary indexes entities, rejecting an alias that names two
+// different canonical entities for the same field.
+func NewAliasDictionary(entities []AliasEntity) (*AliasDictionary, error) {
+	d := &AliasDictionary{
+		Entities: entities,
+		exact:    make(map[string][]aliasTarget),
+		prefixes: make(map[string][]aliasPrefix),
+	}
+
+	for _, entity := range entities {
+		if entity.Canonical == "" {
+			return nil, fmt.Errorf("alias entity without a canonical name")
+		}
+		// the CPE fields ignore the ecosystems
+		fields := []struct {
+			field      string
+			aliases    []string
+			ecosystems []string
+		}{
+			{AliasVendor, entity.Vendors, nil},
+			{AliasNamespace, entity.Namespaces, entity.Ecosystems},
+			{AliasProduct, entity.Products, nil},
+			{AliasName, entity.Names, entity.Ecosystems},
+		}
+		for _, f := range fields {
+			target := aliasTarget{canonical: entity.Canonical, ecosystems: f.ecosystems}
+			for _, alias := range f.aliases {
+				alias = strings.ToLower(strings.TrimSpace(alias))
+				if prefix, ok := strings.CutSuffix(alias, "*"); ok {
+					d.prefixes[f.field] = append(d.prefixes[f.field], aliasPrefix{prefix: prefix, aliasTarget: target})
+					continue
+				}
+				key := f.field + "|" + alias
+				for _, existing := range d.exact[key] {
+					if existing.canonical != target.canonical && overlappingEcosystems(existing.ecosystems, target.ecosystems) {
+						return nil, fmt.Errorf("%s alias %s names both %s and %s", f.field, alias, existing.canonical, target.canonical)
+					}
+				}
+				d.exact[key] = append(d.exact[key], target)
+			}
+		}
+	}
+
+	// the longest prefix is the most specific
+	for field := range d.prefixes {
+		sort.SliceStable(d.prefixes[field], func(i, j int) bool {
+			return len(d.prefixes[field][i].prefix) > len(d.prefixes[field][j].prefix)
+		})
+	}
+	return d, nil
+}
+
+func overlappingEcosystems(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, ecosystem := range a {
+		if slices.Contains(b, ecosystem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the canonical entity value is an alias of for field, in the
+// given purl ecosystem, and whether there is one.
+func (d *AliasDictionary) Resolve(field, ecosystem, value string) (string, bool) {
+	if d == nil || value == "" {
+		return "", false
+	}
+	lower := strings.ToLower(value)
+	applies := func(target aliasTarget) bool {
+		return len(target.ecosystems) == 0 || slices.Contains(target.ecosystems, ecosystem)
+	}
+
+	for _, target := range d.exact[field+"|"+lower] {
+		if applies(target) {
+			return target.canonical, true
+		}
+	}
+	for _, prefix := range d.prefixes[field] {
+		if strings.HasPrefix(lower, prefix.prefix) && applies(prefix.aliasTarget) {
+			return prefix.canonical, true
+		}
+	}
+	return "", false
+}
+
+// ConvertCPEToGuacID converts a CPE like the function of the same name, then
+// resolves its vendor and product.
+func (d *AliasDictionary) ConvertCPEToGuacID(cpe CPE) GuacID {
+	id := ConvertCPEToGuacID(cpe)
+	d.resolve(&id, AliasVendor, &id.Namespace)
+	d.resolve(&id, AliasProduct, &id.Name)
+	return id
+}
+
+// ConvertPurlToGuacID converts a purl like the function of the same name,
+// then resolves its normalized namespace and name.
+func (d *AliasDictionary) ConvertPurlToGuacID(purl Purl) GuacID {
+	id := ConvertPurlToGuacID(purl)
+	d.resolve(&id, AliasNamespace, &id.Namespace)
+	d.resolve(&id, AliasName, &id.Name)
+	return id
+}
+
+// resolve replaces *value by its canonical entity, keeping the replaced
+// spelling in Originals and recording the alias that fired in Aliases.
+func (d *AliasDictionary) resolve(id *GuacID, field string, value *string) {
+	canonical, ok := d.Resolve(field, id.Ecosystem, *value)
+	if !ok || canonical == *value {
+		return
+	}
+
+	originalField := "namespace"
+	if field == AliasProduct || field == AliasName {
+		originalField = "name"
+	}
+	original := originalField + "=" + *value
+	if !slices.Contains(id.Originals, original) {
+		id.Originals = append(id.Originals, original)
+	}
+	id.Aliases = append(id.Aliases, field+":"+*value+"="+canonical)
+	*value = canonical
+}
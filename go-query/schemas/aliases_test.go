@@ -0,0 +1,84 @@
+package schemas
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAliasDictionaryResolve(t *testing.T) {
+	aliases, err := NewAliasDictionary([]AliasEntity{
+		{Canonical: "apache", Vendors: []string{"apache", "Apache Software Foundation"}, Namespaces: []string{"org.apache.*"}, Ecosystems: []string{"maven"}},
+		{Canonical: "curl", Vendors: []string{"haxx"}, Products: []string{"libcurl"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		field     string
+		ecosystem string
+		value     string
+		canonical string
+	}{
+		// aliases ignore case
+		{AliasVendor, "", "APACHE SOFTWARE FOUNDATION", "apache"},
+		{AliasNamespace, "maven", "org.apache.commons", "apache"},
+		// purl aliases only apply in their ecosystems, CPE aliases always
+		{AliasNamespace, "npm", "org.apache.commons", ""},
+		{AliasVendor, "npm", "haxx", "curl"},
+		{AliasProduct, "", "libcurl", "curl"},
+		{AliasName, "", "libcurl", ""},
+	}
+
+	for _, tt := range tests {
+		canonical, ok := aliases.Resolve(tt.field, tt.ecosystem, tt.value)
+		if canonical != tt.canonical || ok != (tt.canonical != "") {
+			t.Errorf("Resolve(%s, %q, %q) = %q, %t; want %q", tt.field, tt.ecosystem, tt.value, canonical, ok, tt.canonical)
+		}
+	}
+
+	var nilAliases *AliasDictionary
+	if _, ok := nilAliases.Resolve(AliasVendor, "", "haxx"); ok {
+		t.Errorf("a nil dictionary resolved an alias")
+	}
+}
+
+func TestAliasDictionaryConvert(t *testing.T) {
+	aliases, err := NewAliasDictionary([]AliasEntity{{Canonical: "curl", Vendors: []string{"haxx"}, Products: []string{"libcurl"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gID := aliases.ConvertCPEToGuacID(CPE{Part: "a", Vendor: "haxx", Product: "libcurl", Version: "7.50.3"})
+	if gID.Namespace != "curl" || gID.Name != "curl" {
+		t.Errorf("ConvertCPEToGuacID resolved %q %q; want curl curl", gID.Namespace, gID.Name)
+	}
+	if want := []string{"namespace=haxx", "name=libcurl"}; !slices.Equal(gID.Originals, want) {
+		t.Errorf("Originals = %v; want %v", gID.Originals, want)
+	}
+	if want := []string{"vendor:haxx=curl", "product:libcurl=curl"}; !slices.Equal(gID.Aliases, want) {
+		t.Errorf("Aliases = %v; want %v", gID.Aliases, want)
+	}
+}
+
+func TestNewAliasDictionaryInvalid(t *testing.T) {
+	tests := [][]AliasEntity{
+		{{Vendors: []string{"haxx"}}},
+		{{Canonical: "curl", Vendors: []string{"haxx"}}, {Canonical: "libcurl", Vendors: []string{"HAXX"}}},
+		{{Canonical: "a", Names: []string{"x"}, Ecosystems: []string{"npm"}}, {Canonical: "b", Names: []string{"x"}}},
+	}
+
+	for _, entities := range tests {
+		if _, err := NewAliasDictionary(entities); err == nil {
+			t.Errorf("NewAliasDictionary(%+v) succeeded; want an error", entities)
+		}
+	}
+
+	// the same alias may name different entities in different ecosystems
+	if _, err := NewAliasDictionary([]AliasEntity{
+		{Canonical: "a", Names: []string{"x"}, Ecosystems: []string{"npm"}},
+		{Canonical: "b", Names: []string{"x"}, Ecosystems: []string{"pypi"}},
+	}); err != nil {
+		t.Errorf("NewAliasDictionary with aliases in different ecosystems: %s", err)
+	}
+}
@@ -0,0 +1,396 @@
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WFNLogical says whether a WFN attribute holds a value or one of the two
+// logical values CPE defines: ANY, matching everything, and NA, meaning the
+// attribute does not apply.
+type WFNLogical int
+
+const (
+	WFNString WFNLogical = iota
+	WFNAny
+	WFNNA
+)
+
+// WFNValue is one attribute of a Well-Formed Name. Value is in WFN form:
+// lowercase, with every character other than letters, digits and underscore
+// quoted by a backslash. Unquoted * and ? are wildcards.
+type WFNValue struct {
+	Logical WFNLogical `json:"logical,omitempty"`
+	Value   string     `json:"value,omitempty"`
+}
+
+var (
+	wfnAny = WFNValue{Logical: WFNAny}
+	wfnNA  = WFNValue{Logical: WFNNA}
+)
+
+func (v WFNValue) IsAny() bool { return v.Logical == WFNAny }
+
+func (v WFNValue) IsNA() bool { return v.Logical == WFNNA }
+
+// Unquoted returns the value with its backslash quoting removed, "" for ANY
+// and "-" for NA, the way the bound forms write them.
+func (v WFNValue) Unquoted() string {
+	switch v.Logical {
+	case WFNAny:
+		return ""
+	case WFNNA:
+		return "-"
+	}
+
+	var b strings.Builder
+	quoted := false
+	for _, r := range v.Value {
+		if r == '\\' && !quoted {
+			quoted = true
+			continue
+		}
+		quoted = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (v WFNValue) String() string {
+	switch v.Logical {
+	case WFNAny:
+		return "ANY"
+	case WFNNA:
+		return "NA"
+	}
+	return `"` + v.Value + `"`
+}
+
+// WFN is a CPE Well-Formed Name as defined by NISTIR 7695, the form both the
+// 2.3 formatted string and the 2.2 URI bind to. Attributes missing from a
+// bound name are ANY.
+type WFN struct {
+	Part      WFNValue `json:"part"`
+	Vendor    WFNValue `json:"vendor"`
+	Product   WFNValue `json:"product"`
+	Version   WFNValue `json:"version"`
+	Update    WFNValue `json:"update"`
+	Edition   WFNValue `json:"edition"`
+	Language  WFNValue `json:"language"`
+	SWEdition WFNValue `json:"sw_edition"`
+	TargetSW  WFNValue `json:"target_sw"`
+	TargetHW  WFNValue `json:"target_hw"`
+	Other     WFNValue `json:"other"`
+}
+
+// attributes lists the attributes in the order the 2.3 formatted string binds
+// them.
+func (w *WFN) attributes() []*WFNValue {
+	return []*WFNValue{
+		&w.Part, &w.Vendor, &w.Product, &w.Version, &w.Update, &w.Edition,
+		&w.Language, &w.SWEdition, &w.TargetSW, &w.TargetHW, &w.Other,
+	}
+}
+
+var wfnAttributeNames = []string{
+	"part", "vendor", "product", "version", "update", "edition",
+	"language", "sw_edition", "target_sw", "target_hw", "other",
+}
+
+// String writes the WFN in the wfn:[...] notation of the spec, leaving out
+// ANY attributes.
+func (w WFN) String() string {
+	parts := []string{}
+	for i, attribute := range w.attributes() {
+		if attribute.IsAny() {
+			continue
+		}
+		parts = append(parts, wfnAttributeNames[i]+"="+attribute.String())
+	}
+	return "wfn:[" + strings.Join(parts, ",") + "]"
+}
+
+// CPE flattens the WFN into a CPE, with ANY attributes left empty and NA
+// attributes set to "-".
+func (w WFN) CPE() CPE {
+	cpe := CPE{
+		Part:      w.Part.Unquoted(),
+		Vendor:    w.Vendor.Unquoted(),
+		Product:   w.Product.Unquoted(),
+		Version:   w.Version.Unquoted(),
+		Update:    w.Update.Unquoted(),
+		Edition:   w.Edition.Unquoted(),
+		Language:  w.Language.Unquoted(),
+		SWEdition: w.SWEdition.Unquoted(),
+		TargetSW:  w.TargetSW.Unquoted(),
+		TargetHW:  w.TargetHW.Unquoted(),
+	}
+	if other := w.Other.Unquoted(); other != "" {
+		cpe.Other = []string{other}
+	}
+	return cpe
+}
+
+// BindFormattedString binds the WFN to a CPE 2.3 formatted string. Quoting
+// is kept except on the period, hyphen and underscore, which the formatted
+// string writes bare.
+func (w WFN) BindFormattedString() string {
+	components := []string{}
+	for _, attribute := range w.attributes() {
+		switch attribute.Logical {
+		case WFNAny:
+			components = append(components, "*")
+		case WFNNA:
+			components = append(components, "-")
+		default:
+			components = append(components, bindFormattedStringValue(attribute.Value))
+		}
+	}
+	return "cpe:2.3:" + strings.Join(components, ":")
+}
+
+func bindFormattedStringValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) {
+			i++
+			if next := value[i]; next != '.' && next != '-' && next != '_' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(value[i])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// NewWFNValue quotes a plain attribute value for a WFN, so that * and ? in it
+// are literal rather than wildcards. "" is ANY and "-" is NA.
+func NewWFNValue(value string) (WFNValue, error) {
+	switch value {
+	case "":
+		return wfnAny, nil
+	case "-":
+		return wfnNA, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if err := writeWFNChar(&b, value[i]); err != nil {
+			return WFNValue{}, err
+		}
+	}
+	return WFNValue{Value: b.String()}, nil
+}
+
+// ParseWFN unbinds a CPE 2.3 formatted string (cpe:2.3:...) or a CPE 2.2 URI
+// (cpe:/...) into a WFN.
+func ParseWFN(cpeStr string) (WFN, error) {
+	cpeStr = strings.TrimSpace(cpeStr)
+	lower := strings.ToLower(cpeStr)
+
+	var wfn WFN
+	var err error
+	switch {
+	case strings.HasPrefix(lower, "cpe:2.3:"):
+		wfn, err = unbindFormattedString(cpeStr[len("cpe:2.3:"):])
+	case strings.HasPrefix(lower, "cpe:/"):
+		wfn, err = unbindURI(cpeStr[len("cpe:/"):])
+	default:
+		return WFN{}, fmt.Errorf("invalid CPE format: %s", cpeStr)
+	}
+	if err != nil {
+		return WFN{}, fmt.Errorf("invalid CPE %s: %s", cpeStr, err)
+	}
+
+	if !wfn.Part.IsAny() && wfn.Part.Value != "a" && wfn.Part.Value != "o" && wfn.Part.Value != "h" {
+		return WFN{}, fmt.Errorf("invalid CPE %s: unknown part %s", cpeStr, wfn.Part)
+	}
+	// a CPE that names no product identifies nothing, and all such CPEs would
+	// share one GuacID
+	if wfn.Product.IsAny() || wfn.Product.IsNA() {
+		return WFN{}, fmt.Errorf("invalid CPE %s: missing product", cpeStr)
+	}
+	return wfn, nil
+}
+
+// unbindFormattedString unbinds the eleven colon separated components that
+// follow cpe:2.3:. A backslash quotes the character after it, including a
+// colon.
+func unbindFormattedString(s string) (WFN, error) {
+	components := []string{}
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ':':
+			components = append(components, s[start:i])
+			start = i + 1
+		}
+	}
+	components = append(components, s[start:])
+
+	wfn := WFN{}
+	attributes := wfn.attributes()
+	if len(components) != len(attributes) {
+		return WFN{}, fmt.Errorf("expected %d components, found %d", len(attributes), len(components))
+	}
+
+	for i, component := range components {
+		value, err := unbindFormattedStringValue(component)
+		if err != nil {
+			return WFN{}, fmt.Errorf("%s: %s", wfnAttributeNames[i], err)
+		}
+		*attributes[i] = value
+	}
+	return wfn, nil
+}
+
+func unbindFormattedStringValue(component string) (WFNValue, error) {
+	switch component {
+	case "":
+		return WFNValue{}, fmt.Errorf("empty component")
+	case "*":
+		return wfnAny, nil
+	case "-":
+		return wfnNA, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(component); i++ {
+		c := component[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(component) {
+				return WFNValue{}, fmt.Errorf("trailing backslash")
+			}
+			i++
+			if err := writeWFNChar(&b, component[i]); err != nil {
+				return WFNValue{}, err
+			}
+		case c == '*' || c == '?':
+			// unquoted, these are wildcards and stay unquoted in the WFN
+			b.WriteByte(c)
+		default:
+			if err := writeWFNChar(&b, c); err != nil {
+				return WFNValue{}, err
+			}
+		}
+	}
+	return WFNValue{Value: b.String()}, nil
+}
+
+// unbindURI unbinds the up to seven colon separated components that follow
+// cpe:/. Components left out or empty are ANY. An edition starting with ~ is
+// packed and holds the extended attributes 2.2 has no components for.
+func unbindURI(s string) (WFN, error) {
+	components := strings.Split(s, ":")
+	if components[0] == "" {
+		return WFN{}, fmt.Errorf("missing part")
+	}
+	if len(components) > 7 {
+		return WFN{}, fmt.Errorf("expected at most 7 components, found %d", len(components))
+	}
+
+	wfn := WFN{}
+	attributes := wfn.attributes()
+	for _, attribute := range attributes {
+		*attribute = wfnAny
+	}
+	for i, component := range components {
+		if &wfn.Edition == attributes[i] && strings.HasPrefix(component, "~") {
+			if err := wfn.unpackEdition(component); err != nil {
+				return WFN{}, fmt.Errorf("edition: %s", err)
+			}
+			continue
+		}
+
+		value, err := unbindURIValue(component)
+		if err != nil {
+			return WFN{}, fmt.Errorf("%s: %s", wfnAttributeNames[i], err)
+		}
+		*attributes[i] = value
+	}
+	return wfn, nil
+}
+
+// unpackEdition unbinds a packed ~edition~sw_edition~target_sw~target_hw~other
+// edition into its five attributes.
+func (w *WFN) unpackEdition(packed string) error {
+	fields := strings.Split(packed[1:], "~")
+	if len(fields) != 5 {
+		return fmt.Errorf("expected 5 packed fields, found %d", len(fields))
+	}
+
+	attributes := []*WFNValue{&w.Edition, &w.SWEdition, &w.TargetSW, &w.TargetHW, &w.Other}
+	for i, field := range fields {
+		value, err := unbindURIValue(field)
+		if err != nil {
+			return err
+		}
+		*attributes[i] = value
+	}
+	return nil
+}
+
+func unbindURIValue(component string) (WFNValue, error) {
+	switch component {
+	case "":
+		return wfnAny, nil
+	case "-":
+		return wfnNA, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(component); i++ {
+		c := component[i]
+		if c != '%' {
+			if err := writeWFNChar(&b, c); err != nil {
+				return WFNValue{}, err
+			}
+			continue
+		}
+
+		if i+2 >= len(component) {
+			return WFNValue{}, fmt.Errorf("truncated percent encoding")
+		}
+		encoded := strings.ToLower(component[i : i+3])
+		i += 2
+		switch encoded {
+		case "%01":
+			b.WriteByte('?')
+			continue
+		case "%02":
+			b.WriteByte('*')
+			continue
+		}
+		var decoded byte
+		if _, err := fmt.Sscanf(encoded[1:], "%02x", &decoded); err != nil {
+			return WFNValue{}, fmt.Errorf("invalid percent encoding %s", encoded)
+		}
+		if err := writeWFNChar(&b, decoded); err != nil {
+			return WFNValue{}, err
+		}
+	}
+	return WFNValue{Value: b.String()}, nil
+}
+
+// writeWFNChar writes c to a WFN value, lowercased and quoted unless it is a
+// letter, digit or underscore.
+func writeWFNChar(b *strings.Builder, c byte) error {
+	switch {
+	case c >= 'A' && c <= 'Z':
+		b.WriteByte(c + 'a' - 'A')
+	case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_':
+		b.WriteByte(c)
+	case c > ' ' && c < 0x7f:
+		b.WriteByte('\\')
+		b.WriteByte(c)
+	default:
+		return fmt.Errorf("invalid character %q", c)
+	}
+	return nil
+}
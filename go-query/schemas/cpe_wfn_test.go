@@ -0,0 +1,133 @@
+package schemas
+
+import "testing"
+
+// TestParseWFN unbinds examples of the CPE naming specification, NISTIR 7695,
+// and checks the WFNs they give.
+func TestParseWFN(t *testing.T) {
+	tests := []struct {
+		name string
+		cpe  string
+		want string
+	}{
+		{
+			name: "uri",
+			cpe:  "cpe:/a:microsoft:internet_explorer:8.0.6001:beta",
+			want: `wfn:[part="a",vendor="microsoft",product="internet_explorer",version="8\.0\.6001",update="beta"]`,
+		},
+		{
+			name: "uri with na update",
+			cpe:  "cpe:/a:hp:insight_diagnostics:7.4.0.1570:-",
+			want: `wfn:[part="a",vendor="hp",product="insight_diagnostics",version="7\.4\.0\.1570",update=NA]`,
+		},
+		{
+			// the packed edition carries the extended attributes, and its own
+			// empty edition is ANY
+			name: "uri packed edition",
+			cpe:  "cpe:/a:hp:insight_diagnostics:7.4.0.1570:-:~~online~win2003~x64~",
+			want: `wfn:[part="a",vendor="hp",product="insight_diagnostics",version="7\.4\.0\.1570",update=NA,sw_edition="online",target_sw="win2003",target_hw="x64"]`,
+		},
+		{
+			name: "uri packed edition with one attribute",
+			cpe:  "cpe:/a:hp:openview_network_manager:7.51:-:~~~linux~~",
+			want: `wfn:[part="a",vendor="hp",product="openview_network_manager",version="7\.51",update=NA,target_sw="linux"]`,
+		},
+		{
+			name: "uri packed edition with edition",
+			cpe:  "cpe:/o:microsoft:windows_xp::sp2:~pro~~~~",
+			want: `wfn:[part="o",vendor="microsoft",product="windows_xp",update="sp2",edition="pro"]`,
+		},
+		{
+			name: "uri unpacked edition",
+			cpe:  "cpe:/o:microsoft:windows_xp::sp2:pro",
+			want: `wfn:[part="o",vendor="microsoft",product="windows_xp",update="sp2",edition="pro"]`,
+		},
+		{
+			// percent-encoded characters are quoted
+			name: "uri percent encoding",
+			cpe:  "cpe:/a:foo%5cbar:big%24money_manager_2010",
+			want: `wfn:[part="a",vendor="foo\\bar",product="big\$money_manager_2010"]`,
+		},
+		{
+			// a tilde outside the packed edition is quoted
+			name: "uri tilde outside edition",
+			cpe:  "cpe:/a:foo~bar:big%7emoney_2010",
+			want: `wfn:[part="a",vendor="foo\~bar",product="big\~money_2010"]`,
+		},
+		{
+			name: "formatted string",
+			cpe:  "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+			want: `wfn:[part="a",vendor="microsoft",product="internet_explorer",version="8\.0\.6001",update="beta"]`,
+		},
+		{
+			name: "formatted string extended attributes",
+			cpe:  `cpe:2.3:a:hp:insight_diagnostics:7\.4\.0\.1570:-:*:*:online:win2003:x64:*`,
+			want: `wfn:[part="a",vendor="hp",product="insight_diagnostics",version="7\.4\.0\.1570",update=NA,sw_edition="online",target_sw="win2003",target_hw="x64"]`,
+		},
+		{
+			name: "formatted string quoted colon",
+			cpe:  `cpe:2.3:a:foo\:bar:product:1.0:*:*:*:*:*:*:*`,
+			want: `wfn:[part="a",vendor="foo\:bar",product="product",version="1\.0"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wfn, err := ParseWFN(tt.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := wfn.String(); got != tt.want {
+				t.Errorf("ParseWFN(%q) = %s; want %s", tt.cpe, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBindFormattedString binds unbound CPEs again as CPE 2.3 formatted
+// strings.
+func TestBindFormattedString(t *testing.T) {
+	tests := []struct {
+		cpe  string
+		want string
+	}{
+		{"cpe:/a:hp:insight_diagnostics:7.4.0.1570:-:~~online~win2003~x64~", "cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*"},
+		{"cpe:/a:foo%5cbar:big%24money_manager_2010", `cpe:2.3:a:foo\\bar:big\$money_manager_2010:*:*:*:*:*:*:*:*`},
+		// periods, hyphens and underscores are written bare
+		{`cpe:2.3:a:hp:insight_diagnostics:7\.4\.0\.1570:-:*:*:online:win2003:x64:*`, "cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*"},
+		{`cpe:2.3:a:foo\:bar:product:1.0:*:*:*:*:*:*:*`, `cpe:2.3:a:foo\:bar:product:1.0:*:*:*:*:*:*:*`},
+	}
+
+	for _, tt := range tests {
+		wfn, err := ParseWFN(tt.cpe)
+		if err != nil {
+			t.Errorf("ParseWFN(%q): %s", tt.cpe, err)
+			continue
+		}
+		if got := wfn.BindFormattedString(); got != tt.want {
+			t.Errorf("ParseWFN(%q).BindFormattedString() = %q; want %q", tt.cpe, got, tt.want)
+		}
+	}
+}
+
+func TestParseWFNInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cpe  string
+	}{
+		{"unknown format", "cpe:microsoft:windows"},
+		{"unknown part", "cpe:2.3:x:microsoft:windows:*:*:*:*:*:*:*:*"},
+		{"too few components", "cpe:2.3:a:microsoft:windows"},
+		{"too many uri components", "cpe:/a:microsoft:windows:xp:sp2:pro:en:extra"},
+		{"any product", "cpe:2.3:a:microsoft:*:*:*:*:*:*:*:*:*"},
+		{"na product", "cpe:2.3:a:microsoft:-:*:*:*:*:*:*:*:*"},
+		{"uri without product", "cpe:/a:microsoft"},
+		{"uri empty part", "cpe:/:microsoft:windows"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseWFN(tt.cpe); err == nil {
+			t.Errorf("%s: ParseWFN(%q) succeeded; want an error", tt.name, tt.cpe)
+		}
+	}
+}
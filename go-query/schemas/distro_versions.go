@@ -0,0 +1,71 @@
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DistroVersion is a distribution package version split into its parts.
+// Release is the Debian revision or the RPM release. Epoch is empty when it
+// is absent or zero.
+type DistroVersion struct {
+	Epoch    string `json:"epoch,omitempty"`
+	Upstream string `json:"upstream,omitempty"`
+	Release  string `json:"release,omitempty"`
+}
+
+// ParseDebianVersion splits a Debian [epoch:]upstream_version[-debian_revision]
+// version. The revision is whatever follows the last hyphen, so upstream
+// versions may contain hyphens of their own.
+func ParseDebianVersion(version string) (DistroVersion, error) {
+	parsed, err := splitDistroVersion(version)
+	if err != nil {
+		return DistroVersion{}, fmt.Errorf("invalid debian version %q: %s", version, err)
+	}
+	if !isDigit(parsed.Upstream[0]) {
+		return DistroVersion{}, fmt.Errorf("invalid debian version %q: upstream version must start with a digit", version)
+	}
+	return parsed, nil
+}
+
+// ParseRPMVersion splits an RPM [epoch:]version-release EVR. The release is
+// optional, as version strings outside of package headers often leave it out.
+func ParseRPMVersion(version string) (DistroVersion, error) {
+	parsed, err := splitDistroVersion(version)
+	if err != nil {
+		return DistroVersion{}, fmt.Errorf("invalid rpm version %q: %s", version, err)
+	}
+	return parsed, nil
+}
+
+func splitDistroVersion(version string) (DistroVersion, error) {
+	parsed := DistroVersion{}
+	remainder := strings.TrimSpace(version)
+
+	if epoch, rest, found := strings.Cut(remainder, ":"); found {
+		if epoch == "" || strings.IndexFunc(epoch, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+			return DistroVersion{}, fmt.Errorf("epoch %q is not a number", epoch)
+		}
+		// a zero epoch is the same as none
+		parsed.Epoch = strings.TrimLeft(epoch, "0")
+		remainder = rest
+	}
+
+	if i := strings.LastIndex(remainder, "-"); i >= 0 {
+		parsed.Release = remainder[i+1:]
+		remainder = remainder[:i]
+		if parsed.Release == "" {
+			return DistroVersion{}, fmt.Errorf("empty release")
+		}
+	}
+
+	if remainder == "" {
+		return DistroVersion{}, fmt.Errorf("empty upstream version")
+	}
+	parsed.Upstream = remainder
+	return parsed, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,55 @@
+package schemas
+
+import "testing"
+
+func TestParseDistroVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (DistroVersion, error)
+		version string
+		want    DistroVersion
+	}{
+		{"debian", ParseDebianVersion, "1.2.3", DistroVersion{Upstream: "1.2.3"}},
+		{"debian revision", ParseDebianVersion, "2.30-1ubuntu1", DistroVersion{Upstream: "2.30", Release: "1ubuntu1"}},
+		{"debian epoch", ParseDebianVersion, "1:2.30-1ubuntu1", DistroVersion{Epoch: "1", Upstream: "2.30", Release: "1ubuntu1"}},
+		{"debian zero epoch", ParseDebianVersion, "0:1.0-1", DistroVersion{Upstream: "1.0", Release: "1"}},
+		// the revision follows the last hyphen
+		{"debian hyphenated upstream", ParseDebianVersion, "1:1.0-rc1-2", DistroVersion{Epoch: "1", Upstream: "1.0-rc1", Release: "2"}},
+		{"debian tilde", ParseDebianVersion, "1.0~rc1-1", DistroVersion{Upstream: "1.0~rc1", Release: "1"}},
+		{"rpm", ParseRPMVersion, "1.2.3-4.el8", DistroVersion{Upstream: "1.2.3", Release: "4.el8"}},
+		{"rpm epoch", ParseRPMVersion, "2:1.2.3-4.el8", DistroVersion{Epoch: "2", Upstream: "1.2.3", Release: "4.el8"}},
+		{"rpm without release", ParseRPMVersion, "1.0^git1", DistroVersion{Upstream: "1.0^git1"}},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.parse(tt.version)
+		if err != nil {
+			t.Errorf("%s: parsing %q: %s", tt.name, tt.version, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: parsing %q = %+v; want %+v", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseDistroVersionsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (DistroVersion, error)
+		version string
+	}{
+		{"debian empty", ParseDebianVersion, ""},
+		{"debian letter first", ParseDebianVersion, "a1.0-1"},
+		{"debian epoch not a number", ParseDebianVersion, "x:1.0-1"},
+		{"debian empty revision", ParseDebianVersion, "1.0-"},
+		{"rpm empty epoch", ParseRPMVersion, ":1.0-1"},
+		{"rpm empty release", ParseRPMVersion, "1.0-"},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.parse(tt.version); err == nil {
+			t.Errorf("%s: parsing %q succeeded; want an error", tt.name, tt.version)
+		}
+	}
+}
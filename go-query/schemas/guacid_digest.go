@@ -0,0 +1,86 @@
+package schemas
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GuacIDDigestVersion is the version of the canonical encoding GuacIDDigest
+// hashes. GuacIDs record the version their digest was computed with; GuacIDs
+// without one were saved before the canonical encoding, and MigrateGuacIDs
+// rekeys them from the digest they were saved under, which for GuacIDs without
+// a kind is LegacyGuacIDDigest.
+const GuacIDDigestVersion = 1
+
+// guacIDDigestHeader starts every version 1 encoding, so that no encoding of
+// one version can hash the same as an encoding of another.
+const guacIDDigestHeader = "guacid/v1\n"
+
+// CanonicalGuacIDEncoding encodes the identifying fields of a GuacID as one
+// line per non-empty value, in a fixed order:
+//
+//	<label> <length>:<value>
+//
+// where length is the number of bytes of value. Values that are lists, other
+// and qualifier (written key=value), get one line per element, sorted. The
+// labels keep equal values of different fields apart and the lengths keep
+// values that contain spaces or newlines from running into the next line.
+// Count, provenance and the other derived fields are not part of it.
+func CanonicalGuacIDEncoding(gID GuacID) string {
+	var b strings.Builder
+	b.WriteString(guacIDDigestHeader)
+
+	field := func(label, value string) {
+		if value == "" {
+			return
+		}
+		b.WriteString(label)
+		b.WriteString(" ")
+		b.WriteString(strconv.Itoa(len(value)))
+		b.WriteString(":")
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+
+	field("kind", gID.Kind)
+	field("algorithm", gID.Algorithm)
+	field("ecosystem", gID.Ecosystem)
+	field("namespace", gID.Namespace)
+	field("name", gID.Name)
+	field("version", gID.Version)
+	field("epoch", gID.Epoch)
+	field("arch", gID.Arch)
+
+	other := append([]string{}, gID.Other...)
+	sort.Strings(other)
+	for _, value := range other {
+		field("other", value)
+	}
+
+	qualifiers := []string{}
+	for key, value := range gID.Qualifiers {
+		qualifiers = append(qualifiers, key+"="+value)
+	}
+	sort.Strings(qualifiers)
+	for _, qualifier := range qualifiers {
+		field("qualifier", qualifier)
+	}
+
+	field("subpath", gID.SubPath)
+	field("pkgrel", gID.PkgRel)
+	field("edition", gID.Edition)
+	field("part", gID.Part)
+	field("language", gID.Language)
+	field("sw_edition", gID.SWEdition)
+	field("target_sw", gID.TargetSW)
+	return b.String()
+}
+
+// GuacIDDigest is the hex SHA-256 of the canonical encoding of a GuacID.
+func GuacIDDigest(gID GuacID) string {
+	hash := sha256.Sum256([]byte(CanonicalGuacIDEncoding(gID)))
+	return fmt.Sprintf("%x", hash)
+}
@@ -0,0 +1,129 @@
+package schemas
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LegacyGuacIDDigest computes the digest GuacIDs were keyed by before they had
+// a kind or digest version: the hash of their non-empty fields concatenated
+// without separators. GuacIDs.json files of that time did not save it, so it
+// is recomputed to map those GuacIDs and their links to current digests.
+func LegacyGuacIDDigest(guacID GuacID) string {
+	var fields []string
+
+	if guacID.Ecosystem != "" {
+		fields = append(fields, guacID.Ecosystem)
+	}
+	if guacID.Namespace != "" {
+		fields = append(fields, guacID.Namespace)
+	}
+	if guacID.Name != "" {
+		fields = append(fields, guacID.Name)
+	}
+	if guacID.Version != "" {
+		fields = append(fields, guacID.Version)
+	}
+	if guacID.Arch != "" {
+		fields = append(fields, guacID.Arch)
+	}
+	if len(guacID.Other) > 0 {
+		sortedOther := append([]string{}, guacID.Other...)
+		sort.Strings(sortedOther)
+		fields = append(fields, sortedOther...)
+	}
+	if guacID.SubPath != "" {
+		fields = append(fields, guacID.SubPath)
+	}
+	if guacID.PkgRel != "" {
+		fields = append(fields, guacID.PkgRel)
+	}
+	if guacID.Edition != "" {
+		fields = append(fields, guacID.Edition)
+	}
+
+	combinedString := strings.Join(fields, "")
+	hash := sha256.Sum256([]byte(combinedString))
+	return fmt.Sprintf("%x", hash)
+}
+
+// IsLegacyGuacID reports whether a GuacID was saved before GuacIDs had a kind
+// or digest version.
+func IsLegacyGuacID(guacID GuacID) bool {
+	return guacID.Kind == "" && guacID.DigestVersion == 0
+}
+
+// UpgradeLegacyGuacID rebuilds the purl or CPE a legacy GuacID was converted
+// from and converts it again, so that it gets the kind and fields a fresh
+// conversion gives. The CPE parser of the time kept the "2.3" of a formatted
+// string, or the part of a URI such as "/a", as the ecosystem, which tells
+// CPEs from purls. It read the attributes of a formatted string one place to
+// the left, and dropped the language, and for formatted strings the edition,
+// so those cannot be recovered. GuacIDs whose other values cannot be put back
+// in place are rejected. Count is kept.
+func UpgradeLegacyGuacID(legacy GuacID) (GuacID, error) {
+	var upgraded GuacID
+	switch {
+	case legacy.Ecosystem == "2.3":
+		if len(legacy.Other) > 0 {
+			return GuacID{}, fmt.Errorf("unable to upgrade legacy GuacID %s: the places of its other CPE attributes are lost", legacy.Name)
+		}
+		update := legacy.Edition
+		if update == "" {
+			update = "*"
+		}
+		cpe, err := ParseCPE(strings.Join([]string{"cpe", "2.3", legacy.Namespace, legacy.Name, legacy.Version, legacy.PkgRel, update, "*", "*", "*", "*", "*", "*"}, ":"))
+		if err != nil {
+			return GuacID{}, fmt.Errorf("unable to upgrade legacy GuacID %s: %s", legacy.Name, err)
+		}
+		upgraded = ConvertCPEToGuacID(cpe)
+	case strings.HasPrefix(legacy.Ecosystem, "/"):
+		if len(legacy.Other) > 0 {
+			return GuacID{}, fmt.Errorf("unable to upgrade legacy GuacID %s: the places of its other CPE attributes are lost", legacy.Name)
+		}
+		components := []string{"cpe", legacy.Ecosystem, legacy.Namespace, legacy.Name, legacy.Version, legacy.PkgRel, legacy.Edition}
+		for components[len(components)-1] == "" {
+			components = components[:len(components)-1]
+		}
+		cpe, err := ParseCPE(strings.Join(components, ":"))
+		if err != nil {
+			return GuacID{}, fmt.Errorf("unable to upgrade legacy GuacID %s: %s", legacy.Name, err)
+		}
+		upgraded = ConvertCPEToGuacID(cpe)
+	case legacy.Ecosystem != "":
+		// the version of a deb was copied into PkgRel, and of the qualifiers
+		// only arch, and for core purls the other qualifier, were kept
+		purl := Purl{
+			Scheme:    "pkg",
+			Type:      legacy.Ecosystem,
+			Namespace: legacy.Namespace,
+			Name:      legacy.Name,
+			Version:   legacy.Version,
+			SubPath:   legacy.SubPath,
+		}
+		qualifiers := make(map[string]string)
+		if legacy.Arch != "" {
+			qualifiers["arch"] = legacy.Arch
+		}
+		for _, other := range legacy.Other {
+			key, value, ok := strings.Cut(other, "|")
+			if !ok || legacy.Ecosystem != "core" {
+				return GuacID{}, fmt.Errorf("unable to upgrade legacy GuacID %s: unknown other value %q", legacy.Name, other)
+			}
+			if key != "" && value != "" {
+				qualifiers[key] = value
+			}
+		}
+		if len(qualifiers) > 0 {
+			purl.Qualifiers = qualifiers
+		}
+		upgraded = ConvertPurlToGuacID(purl)
+	default:
+		return GuacID{}, fmt.Errorf("unable to upgrade legacy GuacID %s: no ecosystem to tell its kind by", legacy.Name)
+	}
+
+	upgraded.Count = legacy.Count
+	return upgraded, nil
+}
@@ -0,0 +1,172 @@
+package schemas
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// GuacIDToPurl renders a purl GuacID back into a purl, undoing the splitting
+// ConvertPurlToGuacID does. It also returns the GuacID fields, by their JSON
+// names, that a purl has no place for.
+func GuacIDToPurl(gID GuacID) (Purl, []string, error) {
+	if gID.Kind != GuacIDKindPurl {
+		return Purl{}, nil, fmt.Errorf("unable to render a %s GuacID as a purl", gID.Kind)
+	}
+	if gID.Ecosystem == "" || gID.Name == "" {
+		return Purl{}, nil, fmt.Errorf("unable to render GuacID %s as a purl: missing type or name", gID.Digest)
+	}
+
+	purl := Purl{
+		Scheme:    "pkg",
+		Type:      gID.Ecosystem,
+		Namespace: gID.Namespace,
+		Name:      gID.Name,
+		Version:   gID.Version,
+		SubPath:   gID.SubPath,
+	}
+	unrepresented := []string{}
+
+	qualifiers := make(map[string]string, len(gID.Qualifiers)+2)
+	for key, value := range gID.Qualifiers {
+		qualifiers[key] = value
+	}
+	if gID.Arch != "" {
+		qualifiers["arch"] = gID.Arch
+	}
+
+	switch gID.Ecosystem {
+	case "deb":
+		if gID.Epoch != "" {
+			purl.Version = gID.Epoch + ":" + purl.Version
+		}
+		if gID.PkgRel != "" {
+			purl.Version += "-" + gID.PkgRel
+		}
+	case "rpm":
+		// the rpm purl spec keeps the epoch in a qualifier
+		if gID.Epoch != "" {
+			qualifiers["epoch"] = gID.Epoch
+		}
+		if gID.PkgRel != "" {
+			purl.Version += "-" + gID.PkgRel
+		}
+	default:
+		if gID.Epoch != "" {
+			unrepresented = append(unrepresented, "epoch")
+		}
+		if gID.PkgRel != "" {
+			unrepresented = append(unrepresented, "pkgrel")
+		}
+	}
+	if len(qualifiers) > 0 {
+		purl.Qualifiers = qualifiers
+	}
+
+	if len(gID.Other) > 0 {
+		unrepresented = append(unrepresented, "other")
+	}
+	for field, value := range map[string]string{"edition": gID.Edition, "part": gID.Part, "language": gID.Language, "sw_edition": gID.SWEdition, "target_sw": gID.TargetSW} {
+		if value != "" {
+			unrepresented = append(unrepresented, field)
+		}
+	}
+	sort.Strings(unrepresented)
+	return purl, unrepresented, nil
+}
+
+// GuacIDToCPE renders a cpe or purl GuacID as a CPE WFN, using the mapping of
+// ConvertCPEToGuacID in reverse. PkgRel only maps to update for cpe GuacIDs;
+// for purls it is a distro release, which means something else. Purl GuacIDs
+// are rendered as applications. It also returns the GuacID fields, by their
+// JSON names, that the CPE has no place for.
+func GuacIDToCPE(gID GuacID) (WFN, []string, error) {
+	if gID.Kind != GuacIDKindCPE && gID.Kind != GuacIDKindPurl {
+		return WFN{}, nil, fmt.Errorf("unable to render a %s GuacID as a CPE", gID.Kind)
+	}
+	if gID.Name == "" {
+		return WFN{}, nil, fmt.Errorf("unable to render GuacID %s as a CPE: missing name", gID.Digest)
+	}
+
+	wfn := WFN{}
+	for _, attribute := range wfn.attributes() {
+		*attribute = wfnAny
+	}
+	part := gID.Part
+	if part == "" && gID.Kind == GuacIDKindPurl {
+		part = "a"
+	}
+
+	unrepresented := []string{}
+	set := func(field string, attribute *WFNValue, value string) {
+		converted, err := NewWFNValue(value)
+		if err != nil {
+			unrepresented = append(unrepresented, field)
+			return
+		}
+		*attribute = converted
+	}
+
+	set("part", &wfn.Part, part)
+	set("namespace", &wfn.Vendor, gID.Namespace)
+	set("name", &wfn.Product, gID.Name)
+	set("version", &wfn.Version, gID.Version)
+	set("edition", &wfn.Edition, gID.Edition)
+	set("arch", &wfn.TargetHW, gID.Arch)
+	set("language", &wfn.Language, gID.Language)
+	set("sw_edition", &wfn.SWEdition, gID.SWEdition)
+	set("target_sw", &wfn.TargetSW, gID.TargetSW)
+	if len(gID.Other) > 0 {
+		set("other", &wfn.Other, gID.Other[0])
+		if len(gID.Other) > 1 {
+			unrepresented = append(unrepresented, "other")
+		}
+	}
+
+	if gID.Kind == GuacIDKindCPE {
+		set("pkgrel", &wfn.Update, gID.PkgRel)
+	} else if gID.PkgRel != "" {
+		unrepresented = append(unrepresented, "pkgrel")
+	}
+
+	if gID.Ecosystem != "" {
+		unrepresented = append(unrepresented, "ecosystem")
+	}
+	if gID.Epoch != "" {
+		unrepresented = append(unrepresented, "epoch")
+	}
+	if len(gID.Qualifiers) > 0 {
+		unrepresented = append(unrepresented, "qualifiers")
+	}
+	if gID.SubPath != "" {
+		unrepresented = append(unrepresented, "subpath")
+	}
+
+	if wfn.Product.IsAny() {
+		return WFN{}, nil, fmt.Errorf("unable to render GuacID %s as a CPE: name %q cannot be bound", gID.Digest, gID.Name)
+	}
+	sort.Strings(unrepresented)
+	return wfn, slices.Compact(unrepresented), nil
+}
+
+// SetCanonicalStrings renders a purl GuacID as its canonical purl and a cpe
+// GuacID as its canonical CPE, recording in Unrepresented the fields that
+// string has no place for. A purl GuacID is not given a CPE, though
+// GuacIDToCPE can build one: no such CPE was ever issued for it. GuacIDs of
+// other kinds, or that cannot be rendered, get neither string.
+func SetCanonicalStrings(gID *GuacID) {
+	gID.CanonicalPurl, gID.CanonicalCPE, gID.Unrepresented = "", "", nil
+
+	switch gID.Kind {
+	case GuacIDKindPurl:
+		if purl, unrepresented, err := GuacIDToPurl(*gID); err == nil {
+			gID.CanonicalPurl = purl.String()
+			gID.Unrepresented = unrepresented
+		}
+	case GuacIDKindCPE:
+		if wfn, unrepresented, err := GuacIDToCPE(*gID); err == nil {
+			gID.CanonicalCPE = wfn.BindFormattedString()
+			gID.Unrepresented = unrepresented
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package schemas
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetCanonicalStrings(t *testing.T) {
+	tests := []struct {
+		name          string
+		gID           GuacID
+		purl          string
+		cpe           string
+		unrepresented []string
+	}{
+		{
+			name: "purl",
+			gID:  GuacID{Kind: GuacIDKindPurl, Ecosystem: "deb", Namespace: "debian", Name: "curl", Version: "7.50.3", Epoch: "1", PkgRel: "1", Arch: "i386"},
+			purl: "pkg:deb/debian/curl@1:7.50.3-1?arch=i386",
+		},
+		{
+			// a purl GuacID is not given a CPE, and the epoch of a type
+			// without one is reported
+			name:          "purl with unrepresented epoch",
+			gID:           GuacID{Kind: GuacIDKindPurl, Ecosystem: "npm", Name: "left-pad", Version: "1.3.0", Epoch: "2"},
+			purl:          "pkg:npm/left-pad@1.3.0",
+			unrepresented: []string{"epoch"},
+		},
+		{
+			name: "cpe",
+			gID:  GuacID{Kind: GuacIDKindCPE, Part: "a", Namespace: "microsoft", Name: "internet_explorer", Version: "8.0.6001", PkgRel: "beta"},
+			cpe:  "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+		},
+		{
+			name:          "cpe with several other attributes",
+			gID:           GuacID{Kind: GuacIDKindCPE, Part: "a", Namespace: "hp", Name: "insight_diagnostics", Other: []string{"a", "b"}},
+			cpe:           "cpe:2.3:a:hp:insight_diagnostics:*:*:*:*:*:*:*:a",
+			unrepresented: []string{"other"},
+		},
+		{
+			name: "artifact",
+			gID:  GuacID{Kind: GuacIDKindArtifact, Algorithm: "sha256", Name: "244fd47e07d1004f0aed9c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gID := tt.gID
+			SetCanonicalStrings(&gID)
+			if gID.CanonicalPurl != tt.purl || gID.CanonicalCPE != tt.cpe {
+				t.Errorf("SetCanonicalStrings = %q, %q; want %q, %q", gID.CanonicalPurl, gID.CanonicalCPE, tt.purl, tt.cpe)
+			}
+			if !slices.Equal(gID.Unrepresented, tt.unrepresented) {
+				t.Errorf("Unrepresented = %v; want %v", gID.Unrepresented, tt.unrepresented)
+			}
+		})
+	}
+}
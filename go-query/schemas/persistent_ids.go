@@ -0,0 +1,71 @@
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gitoid is a git object ID in URI form, gitoid:<type>:<algorithm>:<hash>,
+// the identifier OmniBOR gives artifacts.
+type Gitoid struct {
+	ObjectType string `json:"object_type,omitempty"`
+	Algorithm  string `json:"algorithm,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+}
+
+var gitoidHashLengths = map[string]int{"sha1": 40, "sha256": 64}
+
+// ParseGitoid parses a gitoid URI, lowercasing it.
+func ParseGitoid(gitoidStr string) (Gitoid, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(gitoidStr)), ":")
+	if len(parts) != 4 || parts[0] != "gitoid" {
+		return Gitoid{}, fmt.Errorf("invalid gitoid %s", gitoidStr)
+	}
+
+	gitoid := Gitoid{ObjectType: parts[1], Algorithm: parts[2], Hash: parts[3]}
+	switch gitoid.ObjectType {
+	case "blob", "tree", "commit", "tag":
+	default:
+		return Gitoid{}, fmt.Errorf("invalid gitoid %s: unknown object type %s", gitoidStr, gitoid.ObjectType)
+	}
+	length, ok := gitoidHashLengths[gitoid.Algorithm]
+	if !ok {
+		return Gitoid{}, fmt.Errorf("invalid gitoid %s: unknown algorithm %s", gitoidStr, gitoid.Algorithm)
+	}
+	if len(gitoid.Hash) != length || strings.Trim(gitoid.Hash, "0123456789abcdef") != "" {
+		return Gitoid{}, fmt.Errorf("invalid gitoid %s: malformed %s hash", gitoidStr, gitoid.Algorithm)
+	}
+	return gitoid, nil
+}
+
+func (g Gitoid) String() string {
+	return "gitoid:" + g.ObjectType + ":" + g.Algorithm + ":" + g.Hash
+}
+
+// SWID is the tag ID of an ISO/IEC 19770-2 software identification tag.
+// SPDX writes it as swid:<tagId>, CycloneDX as the tagId of a component.
+type SWID struct {
+	TagID string `json:"tag_id,omitempty"`
+}
+
+// ParseSWID parses a SWID tag ID with or without the swid: prefix. Tag IDs
+// are opaque and compared as they are.
+func ParseSWID(swidStr string) (SWID, error) {
+	tagID := strings.TrimSpace(swidStr)
+	if len(tagID) >= len("swid:") && strings.EqualFold(tagID[:len("swid:")], "swid:") {
+		tagID = tagID[len("swid:"):]
+	}
+	if tagID == "" {
+		return SWID{}, fmt.Errorf("invalid swid %s: empty tag ID", swidStr)
+	}
+	for _, r := range tagID {
+		if r < ' ' || r == 0x7f {
+			return SWID{}, fmt.Errorf("invalid swid %s: control character in tag ID", swidStr)
+		}
+	}
+	return SWID{TagID: tagID}, nil
+}
+
+func (s SWID) String() string {
+	return "swid:" + s.TagID
+}
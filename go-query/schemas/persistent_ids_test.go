@@ -0,0 +1,86 @@
+package schemas
+
+import "testing"
+
+func TestParseGitoid(t *testing.T) {
+	tests := []struct {
+		gitoid string
+		want   string
+	}{
+		{"gitoid:blob:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64", ""},
+		{"gitoid:blob:sha256:fee53a18d32820613c0527aa79be5cb30173c823a9b448fa4817767cc84c6f03", ""},
+		// gitoids are lowercased
+		{" GITOID:Commit:SHA1:261EEB9E9F8B2B4B0D119366DDA99C6FD7D35C64", "gitoid:commit:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64"},
+	}
+
+	for _, tt := range tests {
+		want := tt.want
+		if want == "" {
+			want = tt.gitoid
+		}
+		gitoid, err := ParseGitoid(tt.gitoid)
+		if err != nil {
+			t.Errorf("ParseGitoid(%q): %s", tt.gitoid, err)
+			continue
+		}
+		if got := gitoid.String(); got != want {
+			t.Errorf("ParseGitoid(%q) = %q; want %q", tt.gitoid, got, want)
+		}
+		// a gitoid is an artifact digest of its own
+		if gID := ConvertGitoidToGuacID(gitoid); gID.Kind != GuacIDKindGitoid || gID.Digest != want {
+			t.Errorf("ConvertGitoidToGuacID(%q) = %s %s; want %s %s", tt.gitoid, gID.Kind, gID.Digest, GuacIDKindGitoid, want)
+		}
+	}
+}
+
+func TestParseGitoidInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"gitoid:blob:sha1",
+		"sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64",
+		"gitoid:file:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64",
+		"gitoid:blob:md5:261eeb9e9f8b2b4b0d119366dda99c6f",
+		"gitoid:blob:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c6",
+		"gitoid:blob:sha1:261eeb9e9f8b2b4b0d119366dda99c6fd7d35cxz",
+		"gitoid:blob:sha256:261eeb9e9f8b2b4b0d119366dda99c6fd7d35c64",
+	}
+
+	for _, gitoid := range tests {
+		if _, err := ParseGitoid(gitoid); err == nil {
+			t.Errorf("ParseGitoid(%q) succeeded; want an error", gitoid)
+		}
+	}
+}
+
+func TestParseSWID(t *testing.T) {
+	tests := []struct {
+		swid  string
+		tagID string
+	}{
+		{"swid:com.example.app-1.0.0", "com.example.app-1.0.0"},
+		{"SWID:com.example.app-1.0.0", "com.example.app-1.0.0"},
+		// CycloneDX gives the tag ID alone
+		{"8f8e5b3a-9c2d-4e0f-a1b2-3c4d5e6f7a8b", "8f8e5b3a-9c2d-4e0f-a1b2-3c4d5e6f7a8b"},
+		{" swid:Acme Widget 2.0 ", "Acme Widget 2.0"},
+	}
+
+	for _, tt := range tests {
+		swid, err := ParseSWID(tt.swid)
+		if err != nil {
+			t.Errorf("ParseSWID(%q): %s", tt.swid, err)
+			continue
+		}
+		if swid.TagID != tt.tagID {
+			t.Errorf("ParseSWID(%q) = %q; want %q", tt.swid, swid.TagID, tt.tagID)
+		}
+		if gID := ConvertSWIDToGuacID(swid); gID.Kind != GuacIDKindSWID || gID.Name != tt.tagID {
+			t.Errorf("ConvertSWIDToGuacID(%q) = %s %s; want %s %s", tt.swid, gID.Kind, gID.Name, GuacIDKindSWID, tt.tagID)
+		}
+	}
+
+	for _, swid := range []string{"", "swid:", "swid:tag\x00id"} {
+		if _, err := ParseSWID(swid); err == nil {
+			t.Errorf("ParseSWID(%q) succeeded; want an error", swid)
+		}
+	}
+}
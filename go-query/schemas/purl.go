@@ -0,0 +1,249 @@
+package schemas
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ParsePurl parses a package URL following the purl specification:
+// components are percent-decoded, qualifier keys lowercased, empty qualifiers
+// dropped, subpath segments cleaned and the normalizations of the known types
+// applied. String turns the result back into the canonical purl.
+func ParsePurl(purlStr string) (Purl, error) {
+	remainder := strings.TrimSpace(purlStr)
+	purl := Purl{}
+
+	if i := strings.LastIndex(remainder, "#"); i >= 0 {
+		subpath, err := parsePurlSubPath(remainder[i+1:])
+		if err != nil {
+			return Purl{}, fmt.Errorf("invalid purl %s: %s", purlStr, err)
+		}
+		purl.SubPath = subpath
+		remainder = remainder[:i]
+	}
+
+	if i := strings.LastIndex(remainder, "?"); i >= 0 {
+		qualifiers, err := parsePurlQualifiers(remainder[i+1:])
+		if err != nil {
+			return Purl{}, fmt.Errorf("invalid purl %s: %s", purlStr, err)
+		}
+		purl.Qualifiers = qualifiers
+		remainder = remainder[:i]
+	}
+
+	scheme, remainder, found := strings.Cut(remainder, ":")
+	if !found || strings.ToLower(scheme) != "pkg" {
+		return Purl{}, fmt.Errorf("invalid purl %s: scheme must be pkg", purlStr)
+	}
+	purl.Scheme = "pkg"
+	remainder = strings.TrimLeft(remainder, "/")
+
+	purlType, remainder, found := strings.Cut(remainder, "/")
+	if !found {
+		return Purl{}, fmt.Errorf("invalid purl %s: missing name", purlStr)
+	}
+	purl.Type = strings.ToLower(purlType)
+	if !isPurlType(purl.Type) {
+		return Purl{}, fmt.Errorf("invalid purl %s: invalid type %q", purlStr, purlType)
+	}
+
+	// only an @ in the last segment separates the version; an earlier one
+	// belongs to the namespace, as in pkg:npm/@scope/name
+	if i := strings.LastIndex(remainder, "@"); i > strings.LastIndex(remainder, "/") {
+		version, err := url.PathUnescape(remainder[i+1:])
+		if err != nil {
+			return Purl{}, fmt.Errorf("invalid purl %s: version %s", purlStr, err)
+		}
+		purl.Version = version
+		remainder = remainder[:i]
+	}
+
+	segments := []string{}
+	for _, segment := range strings.Split(strings.Trim(remainder, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return Purl{}, fmt.Errorf("invalid purl %s: %s", purlStr, err)
+		}
+		segments = append(segments, decoded)
+	}
+	if len(segments) == 0 || segments[len(segments)-1] == "" {
+		return Purl{}, fmt.Errorf("invalid purl %s: missing name", purlStr)
+	}
+	purl.Name = segments[len(segments)-1]
+	purl.Namespace = strings.Join(segments[:len(segments)-1], "/")
+
+	if err := normalizePurlType(&purl); err != nil {
+		return Purl{}, fmt.Errorf("invalid purl %s: %s", purlStr, err)
+	}
+	return purl, nil
+}
+
+// String returns the canonical form of the purl, with qualifiers sorted by key.
+func (p Purl) String() string {
+	var b strings.Builder
+	b.WriteString("pkg:")
+	b.WriteString(p.Type)
+	b.WriteString("/")
+
+	if p.Namespace != "" {
+		for _, segment := range strings.Split(p.Namespace, "/") {
+			if segment == "" {
+				continue
+			}
+			b.WriteString(escapePurlComponent(segment))
+			b.WriteString("/")
+		}
+	}
+	b.WriteString(escapePurlComponent(p.Name))
+
+	if p.Version != "" {
+		b.WriteString("@")
+		b.WriteString(escapePurlComponent(p.Version))
+	}
+
+	keys := make([]string, 0, len(p.Qualifiers))
+	for key, value := range p.Qualifiers {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == 0 {
+			b.WriteString("?")
+		} else {
+			b.WriteString("&")
+		}
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(escapePurlComponent(p.Qualifiers[key]))
+	}
+
+	if p.SubPath != "" {
+		segments := []string{}
+		for _, segment := range strings.Split(p.SubPath, "/") {
+			segments = append(segments, escapePurlComponent(segment))
+		}
+		b.WriteString("#")
+		b.WriteString(strings.Join(segments, "/"))
+	}
+	return b.String()
+}
+
+func parsePurlQualifiers(s string) (map[string]string, error) {
+	qualifiers := make(map[string]string)
+	for _, pair := range strings.Split(s, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("qualifier %q has no value", pair)
+		}
+		key = strings.ToLower(key)
+		if !isPurlQualifierKey(key) {
+			return nil, fmt.Errorf("invalid qualifier key %q", key)
+		}
+		if _, exists := qualifiers[key]; exists {
+			return nil, fmt.Errorf("duplicate qualifier %q", key)
+		}
+		value, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("qualifier %s: %s", key, err)
+		}
+		if value == "" {
+			continue
+		}
+		qualifiers[key] = value
+	}
+	if len(qualifiers) == 0 {
+		return nil, nil
+	}
+	return qualifiers, nil
+}
+
+// parsePurlSubPath drops empty, "." and ".." segments, which have no meaning
+// in a subpath.
+func parsePurlSubPath(s string) (string, error) {
+	segments := []string{}
+	for _, segment := range strings.Split(strings.Trim(s, "/"), "/") {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", fmt.Errorf("subpath %s", err)
+		}
+		if decoded == "" || decoded == "." || decoded == ".." {
+			continue
+		}
+		segments = append(segments, decoded)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// normalizePurlType applies the rules the purl types document for their
+// namespace and name.
+func normalizePurlType(purl *Purl) error {
+	switch purl.Type {
+	case "alpm", "apk", "bitbucket", "composer", "deb", "github", "hex":
+		purl.Namespace = strings.ToLower(purl.Namespace)
+		purl.Name = strings.ToLower(purl.Name)
+	case "rpm":
+		purl.Namespace = strings.ToLower(purl.Namespace)
+	case "pypi":
+		purl.Name = strings.ReplaceAll(strings.ToLower(purl.Name), "_", "-")
+	case "huggingface":
+		purl.Version = strings.ToLower(purl.Version)
+	case "maven":
+		if purl.Namespace == "" {
+			return fmt.Errorf("maven purls require a namespace")
+		}
+	case "swift":
+		if purl.Namespace == "" || purl.Version == "" {
+			return fmt.Errorf("swift purls require a namespace and version")
+		}
+	}
+	return nil
+}
+
+func isPurlType(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '+' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func isPurlQualifierKey(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// escapePurlComponent percent-encodes everything but the unreserved
+// characters and the ':' and '+' that the purl specification leaves bare.
+func escapePurlComponent(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_' || c == '~' || c == ':' || c == '+' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
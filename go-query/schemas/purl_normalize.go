@@ -0,0 +1,72 @@
+package schemas
+
+import (
+	"regexp"
+	"strings"
+)
+
+// purlNameRules normalize the namespace and name of a purl the way its
+// package manager compares them, beyond what the purl specification requires.
+var purlNameRules = map[string]func(namespace, name string) (string, string){
+	// PEP 503: case-insensitive, with runs of -, _ and . all meaning -
+	"pypi": func(namespace, name string) (string, string) {
+		return namespace, pep503Separators.ReplaceAllString(strings.ToLower(name), "-")
+	},
+	// scopes are always written with their @, which is sometimes left
+	// percent-encoded, and the registry no longer accepts upper case names
+	"npm": func(namespace, name string) (string, string) {
+		namespace = strings.ToLower(namespace)
+		namespace = strings.TrimPrefix(namespace, "%40")
+		if namespace != "" && !strings.HasPrefix(namespace, "@") {
+			namespace = "@" + namespace
+		}
+		return namespace, strings.ToLower(name)
+	},
+	// the module proxy writes upper case letters as ! followed by the lower
+	// case letter; module paths themselves are case-sensitive
+	"golang": func(namespace, name string) (string, string) {
+		return decodeGoModuleCase(namespace), decodeGoModuleCase(name)
+	},
+	"nuget": func(namespace, name string) (string, string) {
+		return strings.ToLower(namespace), strings.ToLower(name)
+	},
+	// maven has no rule: group and artifact ids are case-sensitive, and the
+	// purl specification does not fold their case
+}
+
+var pep503Separators = regexp.MustCompile(`[-_.]+`)
+
+// NormalizePurl applies the purl type rules and the naming rules of the purl's
+// ecosystem to its namespace and name, so that spellings the ecosystem treats
+// as the same package compare equal.
+func NormalizePurl(purl Purl) Purl {
+	normalized := purl
+	// a type rule that rejects the purl has nothing to normalize
+	_ = normalizePurlType(&normalized)
+
+	if rule, ok := purlNameRules[normalized.Type]; ok {
+		normalized.Namespace, normalized.Name = rule(normalized.Namespace, normalized.Name)
+	}
+	return normalized
+}
+
+func decodeGoModuleCase(path string) string {
+	if !strings.Contains(path, "!") {
+		return path
+	}
+
+	var b strings.Builder
+	bang := false
+	for _, r := range path {
+		if r == '!' {
+			bang = true
+			continue
+		}
+		if bang && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		bang = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
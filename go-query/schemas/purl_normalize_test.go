@@ -0,0 +1,28 @@
+package schemas
+
+import "testing"
+
+func TestNormalizePurl(t *testing.T) {
+	tests := []struct {
+		purl string
+		want string
+	}{
+		{"pkg:pypi/Django_Rest.Framework@3.14.0", "pkg:pypi/django-rest-framework@3.14.0"},
+		{"pkg:npm/%40Angular/Core@16.0.0", "pkg:npm/%40angular/core@16.0.0"},
+		{"pkg:golang/github.com/!azure/azure-sdk-for-go@v1.0.0", "pkg:golang/github.com/Azure/azure-sdk-for-go@v1.0.0"},
+		{"pkg:nuget/Newtonsoft.Json@13.0.1", "pkg:nuget/newtonsoft.json@13.0.1"},
+		// maven group and artifact ids are case-sensitive
+		{"pkg:maven/org.Example/MyLib@1.0", "pkg:maven/org.Example/MyLib@1.0"},
+	}
+
+	for _, tt := range tests {
+		purl, err := ParsePurl(tt.purl)
+		if err != nil {
+			t.Errorf("ParsePurl(%q): %s", tt.purl, err)
+			continue
+		}
+		if got := NormalizePurl(purl).String(); got != tt.want {
+			t.Errorf("NormalizePurl(%q) = %q; want %q", tt.purl, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package schemas
+
+import "testing"
+
+// TestPurlRoundTrip parses the examples of the purl specification and checks
+// that String gives back their canonical form.
+func TestPurlRoundTrip(t *testing.T) {
+	tests := []struct {
+		purl      string
+		canonical string
+	}{
+		{"pkg:bitbucket/birkenfeld/pygments-main@244fd47e07d1014f0aed9c", ""},
+		{"pkg:deb/debian/curl@7.50.3-1?arch=i386&distro=jessie", ""},
+		{"pkg:docker/cassandra@sha256:244fd47e07d1004f0aed9c", ""},
+		{"pkg:gem/ruby-advisory-db-check@0.12.4", ""},
+		{"pkg:github/package-url/purl-spec@244fd47e07d1004f0aed9c", ""},
+		{"pkg:golang/google.golang.org/genproto#googleapis/api/annotations", ""},
+		{"pkg:maven/org.apache.xmlgraphics/batik-anim@1.9.1?packaging=sources", ""},
+		{"pkg:npm/foobar@12.3.1", ""},
+		{"pkg:npm/%40angular/animation@12.3.1", ""},
+		{"pkg:npm/@angular/animation@12.3.1", "pkg:npm/%40angular/animation@12.3.1"},
+		{"pkg:npm/@scope/name", "pkg:npm/%40scope/name"},
+		{"pkg:nuget/EnterpriseLibrary.Common@6.0.1304", ""},
+		{"pkg:pypi/django@1.11.1", ""},
+		{"pkg:rpm/fedora/curl@7.50.3-1.fc25?arch=i386&distro=fedora-25", ""},
+		{"pkg:rpm/opensuse/curl@7.56.1-1.1.?arch=i386&distro=opensuse-tumbleweed", ""},
+		// the scheme and type are case insensitive, and qualifiers are sorted
+		{"PKG:Maven/org.apache.commons/io@1.3.4?type=jar&classifier=sources", "pkg:maven/org.apache.commons/io@1.3.4?classifier=sources&type=jar"},
+		// empty qualifiers and empty or . and .. subpath segments are dropped
+		{"pkg:generic/openssl@1.1.10g?arch=&checksum=sha256:de4d501267da#/./src/../lib/", "pkg:generic/openssl@1.1.10g?checksum=sha256:de4d501267da#src/lib"},
+		{"pkg:golang/github.com/docker/docker@v20.10.7+incompatible", ""},
+	}
+
+	for _, tt := range tests {
+		want := tt.canonical
+		if want == "" {
+			want = tt.purl
+		}
+		purl, err := ParsePurl(tt.purl)
+		if err != nil {
+			t.Errorf("ParsePurl(%q): %s", tt.purl, err)
+			continue
+		}
+		if got := purl.String(); got != want {
+			t.Errorf("ParsePurl(%q).String() = %q; want %q", tt.purl, got, want)
+		}
+		// the canonical form is a fixed point
+		again, err := ParsePurl(want)
+		if err != nil || again.String() != want {
+			t.Errorf("ParsePurl(%q).String() = %q, %v; want it unchanged", want, again.String(), err)
+		}
+	}
+}
+
+func TestParsePurlComponents(t *testing.T) {
+	tests := []struct {
+		purl      string
+		namespace string
+		name      string
+		version   string
+	}{
+		{"pkg:npm/@scope/name", "@scope", "name", ""},
+		{"pkg:npm/@scope/name@1.0.0", "@scope", "name", "1.0.0"},
+		{"pkg:golang/github.com/gorilla/context@234fd47e07d1004f0aed9c", "github.com/gorilla", "context", "234fd47e07d1004f0aed9c"},
+		{"pkg:maven/org.apache.commons/io", "org.apache.commons", "io", ""},
+	}
+
+	for _, tt := range tests {
+		purl, err := ParsePurl(tt.purl)
+		if err != nil {
+			t.Errorf("ParsePurl(%q): %s", tt.purl, err)
+			continue
+		}
+		if purl.Namespace != tt.namespace || purl.Name != tt.name || purl.Version != tt.version {
+			t.Errorf("ParsePurl(%q) = %q %q %q; want %q %q %q", tt.purl, purl.Namespace, purl.Name, purl.Version, tt.namespace, tt.name, tt.version)
+		}
+	}
+}
+
+func TestParsePurlInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"maven/org.apache.commons/io",
+		"pkg:maven",
+		"pkg:maven/",
+		"pkg:n&g/name",
+		"pkg:npm/name@1.0%zz",
+	}
+
+	for _, purl := range tests {
+		if _, err := ParsePurl(purl); err == nil {
+			t.Errorf("ParsePurl(%q) succeeded; want an error", purl)
+		}
+	}
+}
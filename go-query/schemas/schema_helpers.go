@@ -1,12 +1,16 @@
 package schemas
 
 import (
-	"fmt"
 	"strings"
 )
 
-func ConvertPurlToGuacID(purl Purl) GuacID {
+// ConvertPurlToGuacID maps a purl onto a GuacID after normalizing its
+// namespace and name. Values changed by normalization are kept in Originals.
+func ConvertPurlToGuacID(original Purl) GuacID {
+	purl := NormalizePurl(original)
+
 	id := GuacID{
+		Kind:      GuacIDKindPurl,
 		Ecosystem: purl.Type,
 		Namespace: purl.Namespace,
 		Name:      purl.Name,
@@ -15,86 +19,160 @@ func ConvertPurlToGuacID(purl Purl) GuacID {
 		SubPath: purl.SubPath,
 	}
 
-	if purl.Type == "deb" {
-		id.PkgRel = purl.Version
+	if purl.Namespace != original.Namespace {
+		id.Originals = append(id.Originals, "namespace="+original.Namespace)
+	}
+	if purl.Name != original.Name {
+		id.Originals = append(id.Originals, "name="+original.Name)
 	}
 
-	if purl.Type == "core" {
-		id.Other = []string{purl.QualX}
+	// distro versions are split so the same upstream version of different
+	// rebuilds share a Version
+	if parseVersion, ok := distroVersionParsers[purl.Type]; ok && purl.Version != "" {
+		if distroVersion, err := parseVersion(purl.Version); err == nil {
+			id.Epoch = distroVersion.Epoch
+			id.Version = distroVersion.Upstream
+			id.PkgRel = distroVersion.Release
+		}
 	}
 
-	if purl.Type != "cargo" {
-		id.Arch = purl.QualArch
+	for key, value := range purl.Qualifiers {
+		if key == "arch" && purl.Type != "cargo" {
+			id.Arch = value
+			continue
+		}
+		// rpm purls may carry the epoch as a qualifier instead
+		if key == "epoch" && purl.Type == "rpm" && id.Epoch == "" {
+			id.Epoch = strings.TrimLeft(value, "0")
+			continue
+		}
+		if id.Qualifiers == nil {
+			id.Qualifiers = make(map[string]string)
+		}
+		id.Qualifiers[key] = value
 	}
 
 	return id
 }
 
+var distroVersionParsers = map[string]func(string) (DistroVersion, error){
+	"deb": ParseDebianVersion,
+	"rpm": ParseRPMVersion,
+}
+
+// ConvertCPEToGuacID maps a parsed CPE onto a GuacID, keeping every
+// attribute:
+//
+//	part      -> Part        update     -> PkgRel
+//	vendor    -> Namespace   edition    -> Edition
+//	product   -> Name        language   -> Language
+//	version   -> Version     sw_edition -> SWEdition
+//	target_hw -> Arch        target_sw  -> TargetSW
+//	other     -> Other
+//
+// Ecosystem is left empty: it names a purl type, which target_sw is not. NA
+// attributes carry no value to identify software by, so like ANY they are
+// left empty.
 func ConvertCPEToGuacID(cpe CPE) GuacID {
+	var other []string
+	for _, value := range cpe.Other {
+		if value = cpeAttribute(value); value != "" {
+			other = append(other, value)
+		}
+	}
+
 	return GuacID{
-		Ecosystem: cpe.TargetSW,
-		Namespace: cpe.Vendor,
-		Name:      cpe.Product,
-		Version:   cpe.Version,
-		Arch:      cpe.TargetHW,
-		Other:     cpe.Other,
-		PkgRel:    cpe.Update,
-		Edition:   cpe.Edition,
+		Kind:      GuacIDKindCPE,
+		Part:      cpeAttribute(cpe.Part),
+		Namespace: cpeAttribute(cpe.Vendor),
+		Name:      cpeAttribute(cpe.Product),
+		Version:   cpeAttribute(cpe.Version),
+		Arch:      cpeAttribute(cpe.TargetHW),
+		Other:     other,
+		PkgRel:    cpeAttribute(cpe.Update),
+		Edition:   cpeAttribute(cpe.Edition),
+		Language:  cpeAttribute(cpe.Language),
+		SWEdition: cpeAttribute(cpe.SWEdition),
+		TargetSW:  cpeAttribute(cpe.TargetSW),
 		//subpath does not exist for CPE
 	}
 }
 
-func ParseCPE(cpeStr string) (CPE, error) {
-	// Format: cpe:/<part>:<vendor>:<product>:<version>:<update>:<edition>:<language>:<sw_edition>:<other>:<other>...
-	parts := strings.Split(cpeStr, ":")
-	if len(parts) < 6 || parts[1] == "*" || parts[2] == "*" || parts[3] == "*" || parts[4] == "*" || parts[5] == "*" {
-		return CPE{}, fmt.Errorf("invalid CPE format: %s", cpeStr)
+func cpeAttribute(value string) string {
+	if value == "-" {
+		return ""
 	}
+	return value
+}
 
-	targetSW := parts[1]
-	vendor := parts[2]
-	product := parts[3]
-	version := parts[4]
-	update := parts[5]
-
-	edition := ""
-	language := ""
-	swEdition := ""
-
-	other := []string{}
+// ConvertArtifactToGuacID identifies an artifact by its content hash alone, so
+// the digest is the GuacID digest as is, prefixed by the normalized algorithm.
+func ConvertArtifactToGuacID(artifact Artifact) GuacID {
+	algorithm := NormalizeDigestAlgorithm(artifact.Algorithm)
+	digest := strings.ToLower(strings.TrimSpace(artifact.Digest))
+	digest = strings.TrimPrefix(digest, algorithm+":")
 
-	if len(parts) > 6 && parts[6] != "*" {
-		edition = parts[6]
+	return GuacID{
+		Kind:      GuacIDKindArtifact,
+		Algorithm: algorithm,
+		Digest:    algorithm + ":" + digest,
 	}
+}
 
-	if len(parts) > 7 && parts[7] != "*" {
-		language = parts[7]
+// ConvertGitoidToGuacID identifies an artifact by its gitoid, which like a
+// content hash is its own digest.
+func ConvertGitoidToGuacID(gitoid Gitoid) GuacID {
+	return GuacID{
+		Kind:      GuacIDKindGitoid,
+		Algorithm: gitoid.Algorithm,
+		Digest:    gitoid.String(),
 	}
+}
 
-	if len(parts) > 8 && parts[8] != "*" {
-		swEdition = parts[8]
+// ConvertSWIDToGuacID names a GuacID by its SWID tag ID.
+func ConvertSWIDToGuacID(swid SWID) GuacID {
+	return GuacID{
+		Kind: GuacIDKindSWID,
+		Name: swid.TagID,
 	}
+}
 
-	if len(parts) > 9 {
-		for _, val := range parts[9:] {
-			if val == "*" {
-				continue
-			}
-			other = append(other, val)
-		}
+var digestAlgorithms = map[string]string{
+	"md5":        "md5",
+	"sha1":       "sha1",
+	"sha224":     "sha224",
+	"sha256":     "sha256",
+	"sha384":     "sha384",
+	"sha512":     "sha512",
+	"sha3224":    "sha3-224",
+	"sha3256":    "sha3-256",
+	"sha3384":    "sha3-384",
+	"sha3512":    "sha3-512",
+	"blake2b256": "blake2b-256",
+	"blake2b384": "blake2b-384",
+	"blake2b512": "blake2b-512",
+	"blake3":     "blake3",
+}
+
+// NormalizeDigestAlgorithm maps the spellings used by SPDX, CycloneDX and GUAC
+// (SHA256, SHA-256, sha_256, ...) onto one lowercase name.
+func NormalizeDigestAlgorithm(algorithm string) string {
+	key := strings.ToLower(strings.TrimSpace(algorithm))
+	key = strings.NewReplacer("-", "", "_", "", " ", "", "/", "").Replace(key)
+	if normalized, ok := digestAlgorithms[key]; ok {
+		return normalized
 	}
+	return key
+}
 
-	return CPE{
-		TargetSW:  targetSW,
-		Vendor:    vendor,
-		Product:   product,
-		Version:   version,
-		Update:    update,
-		Edition:   edition,
-		Language:  language,
-		SWEdition: swEdition,
-		Other:     other,
-	}, nil
+// ParseCPE parses a CPE 2.3 formatted string or 2.2 URI. Attributes that are
+// ANY are left empty and attributes that are NA are set to "-".
+func ParseCPE(cpeStr string) (CPE, error) {
+	wfn, err := ParseWFN(cpeStr)
+	if err != nil {
+		return CPE{}, err
+	}
+	return wfn.CPE(), nil
 }
 
 func GuacIDNodeID(node *GuacIDNode) string {
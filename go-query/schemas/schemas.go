@@ -8,17 +8,121 @@ type GuacIDArtifact struct {
 }
 
 type GuacID struct {
-	Digest    string   `json:"digest,omitempty"`
+	Digest string `json:"digest,omitempty"`
+	// DigestVersion is the GuacIDDigestVersion Digest was computed with, 0
+	// for digests saved before the canonical encoding.
+	DigestVersion int `json:"digest_version,omitempty"`
+
 	Count     int64    `json:"count,omitempty"`
+	Kind      string   `json:"kind,omitempty"`
+	Algorithm string   `json:"algorithm,omitempty"`
 	Ecosystem string   `json:"ecosystem,omitempty"`
 	Namespace string   `json:"namespace,omitempty"`
 	Name      string   `json:"name,omitempty"`
 	Version   string   `json:"version,omitempty"`
+	Epoch     string   `json:"epoch,omitempty"`
 	Arch      string   `json:"arch,omitempty"`
 	Other     []string `json:"other,omitempty"`
 	SubPath   string   `json:"subpath,omitempty"`
 	PkgRel    string   `json:"pkgrel,omitempty"`
 	Edition   string   `json:"edition,omitempty"`
+
+	// Part, Language, SWEdition and TargetSW hold the CPE attributes that
+	// have no purl counterpart; see ConvertCPEToGuacID.
+	Part      string `json:"part,omitempty"`
+	Language  string `json:"language,omitempty"`
+	SWEdition string `json:"sw_edition,omitempty"`
+	TargetSW  string `json:"target_sw,omitempty"`
+
+	// Qualifiers holds the purl qualifiers not lifted into a field of their
+	// own, such as distro or upstream.
+	Qualifiers map[string]string `json:"qualifiers,omitempty"`
+	// Originals records the field=value spellings seen before normalization.
+	Originals []string `json:"originals,omitempty"`
+	// Aliases records the aliases of an AliasDictionary that fired, as
+	// <field>:<alias>=<canonical>.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// CanonicalPurl and CanonicalCPE render the GuacID back into identifier
+	// strings, and Unrepresented names the fields, by their JSON names, that
+	// the string leaves out. They are derived from the fields above and not
+	// part of the digest.
+	CanonicalPurl string   `json:"purl,omitempty"`
+	CanonicalCPE  string   `json:"cpe,omitempty"`
+	Unrepresented []string `json:"unrepresented,omitempty"`
+
+	Provenance []GuacIDProvenance `json:"provenance,omitempty"`
+}
+
+// GuacIDProvenance names one GUAC node that produced a GuacID.
+type GuacIDProvenance struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Origin string `json:"origin,omitempty"`
+	// Document identifies the SBOM or other document the node was ingested
+	// from; a GuacID is counted once per document.
+	Document string `json:"document,omitempty"`
+}
+
+const (
+	ProvenanceArtifact    = "artifact"
+	ProvenanceHasMetadata = "has_metadata"
+	ProvenancePackage     = "package"
+	ProvenanceSBOM        = "sbom"
+)
+
+const (
+	GuacIDKindCPE      = "cpe"
+	GuacIDKindPurl     = "purl"
+	GuacIDKindArtifact = "artifact"
+	GuacIDKindGitoid   = "gitoid"
+	GuacIDKindSWID     = "swid"
+)
+
+// GuacIDLink records evidence that two GuacIDs, given by digest, name the same
+// software.
+type GuacIDLink struct {
+	Source   string   `json:"source"`
+	Target   string   `json:"target"`
+	Kind     string   `json:"kind"`
+	Count    int64    `json:"count,omitempty"`
+	Evidence []string `json:"evidence,omitempty"`
+	// Score is the confidence of links that are inferred rather than
+	// observed, between 0 and 1.
+	Score float64 `json:"score,omitempty"`
+}
+
+const (
+	// GuacIDLinkOccurrence links a package to an artifact it occurs as, from
+	// IsOccurrence nodes and the occurrences included in HasSBOM nodes.
+	GuacIDLinkOccurrence = "occurrence"
+	// GuacIDLinkSBOMSubject links the package and artifact subjects of SBOMs
+	// that were ingested from the same document.
+	GuacIDLinkSBOMSubject = "sbom_subject"
+	// GuacIDLinkSLSABuiltFrom links a SLSA subject to its build materials. It is
+	// provenance rather than identity and is kept apart by its kind.
+	GuacIDLinkSLSABuiltFrom = "slsa_built_from"
+	// GuacIDLinkNextVersion links a package version to the next newer version
+	// of the same package, ordered by the rules of its ecosystem.
+	GuacIDLinkNextVersion = "next_version"
+	// GuacIDLinkPersistentID links a package or artifact to a gitoid or SWID
+	// tag ID that a HasMetadata node attaches to it.
+	GuacIDLinkPersistentID = "persistent_id"
+	// GuacIDLinkMetadataSubject links a package or artifact to a CPE that a
+	// HasMetadata node attaches to it.
+	GuacIDLinkMetadataSubject = "metadata_subject"
+	// GuacIDLinkCPEPurlMatch links a CPE to a purl the matcher found to name
+	// the same software, scored by how well their fields agree.
+	GuacIDLinkCPEPurlMatch = "cpe_purl_match"
+	// GuacIDLinkNearDuplicate links GuacIDs whose name trigrams and
+	// attributes a MinHash LSH search estimated to be alike, scored by the
+	// estimated Jaccard index.
+	GuacIDLinkNearDuplicate = "near_duplicate"
+)
+
+type Artifact struct {
+	Algorithm string `json:"algorithm,omitempty"`
+	Digest    string `json:"digest,omitempty"`
 }
 
 type Purl struct {
@@ -27,12 +131,13 @@ type Purl struct {
 	Namespace string `json:"namespace,omitempty"`
 	Name      string `json:"name,omitempty"`
 	Version   string `json:"version,omitempty"`
-	QualArch  string `json:"qual_arch,omitempty"`
-	QualX     string `json:"qual_x,omitempty"`
 	SubPath   string `json:"subpath,omitempty"`
+
+	Qualifiers map[string]string `json:"qualifiers,omitempty"`
 }
 
 type CPE struct {
+	Part      string   `json:"part,omitempty"`
 	TargetSW  string   `json:"target_sw,omitempty"`
 	Vendor    string   `json:"vendor,omitempty"`
 	Product   string   `json:"product,omitempty"`
@@ -46,22 +151,26 @@ type CPE struct {
 }
 
 type Community struct {
-	CommunityID string
-	Size        int
-	GraphSubset *graph.Graph[string, *GuacIDNode]
+	CommunityID string                            `json:"community_id"`
+	Size        int                               `json:"size"`
+	Nodes       []string                          `json:"nodes,omitempty"`
+	GraphSubset *graph.Graph[string, *GuacIDNode] `json:"-"`
 }
 
 type GuacIDNode struct {
-	NodeID     string
-	NodeType   NodeHardness
-	NodeWeight float32
+	NodeID     string       `json:"node_id"`
+	NodeType   NodeHardness `json:"node_type"`
+	NodeWeight float32      `json:"node_weight,omitempty"`
 }
 
 type GuacIDEdge struct {
-	EdgeID  string
-	Source  string
-	Target  string
-	Counter int64
+	EdgeID  string `json:"edge_id,omitempty"`
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Counter int64  `json:"counter,omitempty"`
+	// Weight is the similarity of the names an inferred edge joins, between 0
+	// and 1. Edges between observed attributes leave it 0.
+	Weight float64 `json:"weight,omitempty"`
 }
 
 type NodeHardness int
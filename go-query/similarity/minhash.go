@@ -0,0 +1,225 @@
+package similarity
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+// MinHash computes MinHash signatures: for each of its hash functions, the
+// smallest hash of any feature of a set. Two sets agree on a signature entry
+// with a probability equal to their Jaccard index.
+type MinHash struct {
+	seeds []uint64
+}
+
+// NewMinHash returns a MinHash with numHashes hash functions derived from
+// seed; fewer than one gives empty signatures. Signatures are only comparable
+// between MinHashes of the same size and seed.
+func NewMinHash(numHashes int, seed uint64) *MinHash {
+	m := &MinHash{seeds: make([]uint64, max(numHashes, 0))}
+	for i := range m.seeds {
+		seed = splitMix64(seed)
+		m.seeds[i] = seed
+	}
+	return m
+}
+
+// Signature returns the MinHash signature of a set of features. Duplicate
+// features do not change it.
+func (m *MinHash) Signature(features []string) []uint64 {
+	signature := make([]uint64, len(m.seeds))
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+	for _, feature := range features {
+		base := hashString(feature)
+		for i, seed := range m.seeds {
+			if h := splitMix64(base ^ seed); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// EstimateJaccard estimates the Jaccard index of two sets from their
+// signatures, as the share of entries they agree on.
+func EstimateJaccard(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	agree := 0
+	for i := range a {
+		if a[i] == b[i] {
+			agree++
+		}
+	}
+	return float64(agree) / float64(len(a))
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// splitMix64 is the finalizer of the SplitMix64 generator, which spreads
+// nearby inputs over the whole range.
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// LSHIndex finds sets likely to be near-duplicates without comparing every
+// pair. Signatures are cut into bands of rows entries, and sets whose
+// signatures are equal in any band land in the same bucket. Sets with a
+// Jaccard index s share a bucket with probability 1-(1-s^rows)^bands, which
+// rises steeply around (1/bands)^(1/rows).
+type LSHIndex struct {
+	minHash    *MinHash
+	bands      int
+	rows       int
+	keys       []string
+	signatures [][]uint64
+	buckets    []map[uint64][]int
+}
+
+// NewLSHIndex returns an empty index of signatures of bands*rows entries. It
+// needs at least one band of one row.
+func NewLSHIndex(bands, rows int, seed uint64) (*LSHIndex, error) {
+	if bands < 1 || rows < 1 {
+		return nil, fmt.Errorf("invalid LSH index of %d bands of %d rows, both must be at least 1", bands, rows)
+	}
+	x := &LSHIndex{
+		minHash: NewMinHash(bands*rows, seed),
+		bands:   bands,
+		rows:    rows,
+		buckets: make([]map[uint64][]int, bands),
+	}
+	for band := range x.buckets {
+		x.buckets[band] = make(map[uint64][]int)
+	}
+	return x, nil
+}
+
+// Threshold is the Jaccard index at which sets are about as likely to share a
+// bucket as not.
+func (x *LSHIndex) Threshold() float64 {
+	return math.Pow(1/float64(x.bands), 1/float64(x.rows))
+}
+
+func (x *LSHIndex) Len() int {
+	return len(x.keys)
+}
+
+// Add indexes a set of features under key. An empty set has nothing to be
+// alike in and is not indexed.
+func (x *LSHIndex) Add(key string, features []string) {
+	if len(features) == 0 {
+		return
+	}
+	signature := x.minHash.Signature(features)
+	id := len(x.keys)
+	x.keys = append(x.keys, key)
+	x.signatures = append(x.signatures, signature)
+	for band, bucket := range x.bandHashes(signature) {
+		x.buckets[band][bucket] = append(x.buckets[band][bucket], id)
+	}
+}
+
+func (x *LSHIndex) bandHashes(signature []uint64) []uint64 {
+	hashes := make([]uint64, x.bands)
+	for band := range hashes {
+		h := uint64(band)
+		for _, value := range signature[band*x.rows : (band+1)*x.rows] {
+			h = splitMix64(h ^ value)
+		}
+		hashes[band] = h
+	}
+	return hashes
+}
+
+// Neighbor is an indexed key found by Query, with the estimated Jaccard index
+// of its set and the queried one.
+type Neighbor struct {
+	Key     string  `json:"key"`
+	Jaccard float64 `json:"jaccard"`
+}
+
+// Query returns the indexed keys sharing a bucket with a set of features whose
+// estimated Jaccard index is at least minJaccard, most alike first.
+func (x *LSHIndex) Query(features []string, minJaccard float64) []Neighbor {
+	if len(features) == 0 {
+		return nil
+	}
+	signature := x.minHash.Signature(features)
+	seen := make(map[int]bool)
+	neighbors := []Neighbor{}
+	for band, bucket := range x.bandHashes(signature) {
+		for _, id := range x.buckets[band][bucket] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if jaccard := EstimateJaccard(signature, x.signatures[id]); jaccard >= minJaccard {
+				neighbors = append(neighbors, Neighbor{Key: x.keys[id], Jaccard: jaccard})
+			}
+		}
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Jaccard != neighbors[j].Jaccard {
+			return neighbors[i].Jaccard > neighbors[j].Jaccard
+		}
+		return neighbors[i].Key < neighbors[j].Key
+	})
+	return neighbors
+}
+
+// Pair is two keys of an LSHIndex with the estimated Jaccard index of their
+// sets.
+type Pair struct {
+	A       string  `json:"a"`
+	B       string  `json:"b"`
+	Jaccard float64 `json:"jaccard"`
+}
+
+// Pairs returns every pair of keys sharing a bucket whose estimated Jaccard
+// index is at least minJaccard, most alike first. Buckets of more than
+// maxBucket keys are skipped, since comparing within them is quadratic again;
+// 0 compares every bucket.
+func (x *LSHIndex) Pairs(minJaccard float64, maxBucket int) []Pair {
+	seen := make(map[[2]int]bool)
+	pairs := []Pair{}
+	for _, buckets := range x.buckets {
+		for _, ids := range buckets {
+			if len(ids) < 2 || (maxBucket > 0 && len(ids) > maxBucket) {
+				continue
+			}
+			for i, a := range ids {
+				for _, b := range ids[i+1:] {
+					if seen[[2]int{a, b}] {
+						continue
+					}
+					seen[[2]int{a, b}] = true
+					if jaccard := EstimateJaccard(x.signatures[a], x.signatures[b]); jaccard >= minJaccard {
+						pairs = append(pairs, Pair{A: x.keys[a], B: x.keys[b], Jaccard: jaccard})
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Jaccard != pairs[j].Jaccard {
+			return pairs[i].Jaccard > pairs[j].Jaccard
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
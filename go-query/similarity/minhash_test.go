@@ -0,0 +1,68 @@
+package similarity
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestEstimateJaccard(t *testing.T) {
+	minHash := NewMinHash(512, 1)
+
+	// a and b share 50 of 150 features, a Jaccard index of 1/3
+	var a, b []string
+	for i := 0; i < 100; i++ {
+		a = append(a, fmt.Sprint("feature", i))
+		b = append(b, fmt.Sprint("feature", i+50))
+	}
+	if got := EstimateJaccard(minHash.Signature(a), minHash.Signature(b)); math.Abs(got-1.0/3) > 0.05 {
+		t.Errorf("EstimateJaccard = %.3f; want about %.3f", got, 1.0/3)
+	}
+
+	// duplicate features and their order do not change the signature
+	reordered := append(slices.Clone(a), a[0], a[1])
+	slices.Reverse(reordered)
+	if !slices.Equal(minHash.Signature(a), minHash.Signature(reordered)) {
+		t.Errorf("Signature changed with duplicate and reordered features")
+	}
+	if got := EstimateJaccard(minHash.Signature(a), minHash.Signature(reordered)); got != 1 {
+		t.Errorf("EstimateJaccard of equal sets = %.3f; want 1", got)
+	}
+}
+
+func TestLSHIndex(t *testing.T) {
+	if _, err := NewLSHIndex(0, 4, 1); err == nil {
+		t.Errorf("NewLSHIndex(0, 4) succeeded; want an error")
+	}
+	if _, err := NewLSHIndex(16, 0, 1); err == nil {
+		t.Errorf("NewLSHIndex(16, 0) succeeded; want an error")
+	}
+
+	index, err := NewLSHIndex(32, 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"kubernetes-client-java", "kubernetes_client_java", "left-pad", "lodash"} {
+		index.Add(name, NGrams(name, 3))
+	}
+	// an empty set is not indexed
+	index.Add("empty", nil)
+	if index.Len() != 4 {
+		t.Errorf("Len() = %d; want 4", index.Len())
+	}
+
+	neighbors := index.Query(NGrams("kubernetes-client-java", 3), 0.5)
+	if len(neighbors) != 2 || neighbors[0].Key != "kubernetes-client-java" || neighbors[0].Jaccard != 1 || neighbors[1].Key != "kubernetes_client_java" {
+		t.Errorf("Query(kubernetes-client-java) = %v; want itself, then kubernetes_client_java", neighbors)
+	}
+
+	pairs := index.Pairs(0.5, 0)
+	if len(pairs) != 1 || pairs[0].A != "kubernetes-client-java" || pairs[0].B != "kubernetes_client_java" {
+		t.Errorf("Pairs = %v; want only the two kubernetes-client-java spellings", pairs)
+	}
+	// buckets larger than maxBucket are skipped
+	if pairs := index.Pairs(0.5, 1); len(pairs) != 0 {
+		t.Errorf("Pairs with a bucket limit of 1 = %v; want none", pairs)
+	}
+}
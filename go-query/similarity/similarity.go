@@ -0,0 +1,180 @@
+// Package similarity scores how alike two identifier names are, for names
+// that differ by more than the normalization GuacIDs already get.
+package similarity
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// StripSeparators lowercases a name and drops every rune that is not a letter
+// or a digit, so that log4j-core, log4j_core and Log4j.Core are the same.
+func StripSeparators(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SameIgnoringSeparators reports whether two names differ only in case and
+// separators.
+func SameIgnoringSeparators(a, b string) bool {
+	return StripSeparators(a) == StripSeparators(b)
+}
+
+// Tokens splits a lowercased name on everything that is not a letter or a
+// digit, as python-requests into python and requests.
+func Tokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// EditSimilarity is one minus the Levenshtein distance of the names without
+// separators, normalized by the longer of them.
+func EditSimilarity(a, b string) float64 {
+	ra, rb := []rune(StripSeparators(a)), []rune(StripSeparators(b))
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// TokenJaccard is the Jaccard index of the token sets of the names.
+func TokenJaccard(a, b string) float64 {
+	return jaccard(set(Tokens(a)), set(Tokens(b)))
+}
+
+// NGrams returns the distinct rune n-grams of a name without separators. A
+// name shorter than n is its own only n-gram.
+func NGrams(name string, n int) []string {
+	runes := []rune(StripSeparators(name))
+	if len(runes) == 0 {
+		return nil
+	}
+	if len(runes) <= n {
+		return []string{string(runes)}
+	}
+	seen := make(map[string]bool)
+	grams := []string{}
+	for i := 0; i+n <= len(runes); i++ {
+		gram := string(runes[i : i+n])
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// NGramOverlap is the Dice coefficient of the n-grams of the names, which
+// rewards a shared stem however the rest is spelled.
+func NGramOverlap(a, b string, n int) float64 {
+	ga, gb := set(NGrams(a, n)), set(NGrams(b, n))
+	if len(ga)+len(gb) == 0 {
+		return 1
+	}
+	shared := 0
+	for gram := range ga {
+		if gb[gram] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ga)+len(gb))
+}
+
+func set(values []string) map[string]bool {
+	s := make(map[string]bool, len(values))
+	for _, value := range values {
+		s[value] = true
+	}
+	return s
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for value := range a {
+		if b[value] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Features are the measures of how alike two names are, each between 0 and 1.
+type Features struct {
+	Edit                   float64 `json:"edit"`
+	Jaccard                float64 `json:"jaccard"`
+	NGram                  float64 `json:"ngram"`
+	SameIgnoringSeparators bool    `json:"same_ignoring_separators"`
+}
+
+// Compare measures two names, comparing trigrams.
+func Compare(a, b string) Features {
+	return Features{
+		Edit:                   EditSimilarity(a, b),
+		Jaccard:                TokenJaccard(a, b),
+		NGram:                  NGramOverlap(a, b, 3),
+		SameIgnoringSeparators: SameIgnoringSeparators(a, b),
+	}
+}
+
+// Weights say how much each feature contributes to Score.
+type Weights struct {
+	Edit    float64
+	Jaccard float64
+	NGram   float64
+}
+
+// DefaultWeights trust the n-grams most, since they hold up best when one
+// name is the other with a prefix or a suffix added.
+func DefaultWeights() Weights {
+	return Weights{Edit: 0.3, Jaccard: 0.3, NGram: 0.4}
+}
+
+// Score combines the features into one similarity, rounded to 3 decimals.
+// Names that are the same ignoring separators score 1.
+func (f Features) Score(w Weights) float64 {
+	if f.SameIgnoringSeparators {
+		return 1
+	}
+	total := w.Edit + w.Jaccard + w.NGram
+	if total == 0 {
+		return 0
+	}
+	score := (w.Edit*f.Edit + w.Jaccard*f.Jaccard + w.NGram*f.NGram) / total
+	return math.Round(score*1000) / 1000
+}
+
+// Score is Compare(a, b).Score(DefaultWeights()).
+func Score(a, b string) float64 {
+	return Compare(a, b).Score(DefaultWeights())
+}
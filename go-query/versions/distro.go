@@ -0,0 +1,334 @@
+package versions
+
+import (
+	"fmt"
+	"strings"
+
+	"go-query/schemas"
+)
+
+// debianScheme orders versions as dpkg does: by epoch, then upstream version,
+// then revision, the last two with dpkg's verrevcmp.
+type debianScheme struct{}
+
+func (debianScheme) Name() string { return "deb" }
+
+func (debianScheme) Compare(a, b string) (int, error) {
+	x, err := schemas.ParseDebianVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := schemas.ParseDebianVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if c := compareDigits(x.Epoch, y.Epoch); c != 0 {
+		return c, nil
+	}
+	if c := verrevcmp(x.Upstream, y.Upstream); c != 0 {
+		return c, nil
+	}
+	return verrevcmp(x.Release, y.Release), nil
+}
+
+// dpkgOrder ranks a character of a non-digit run: ~ before the end of the
+// string, which is before letters, which are before everything else.
+func dpkgOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isLetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	}
+	return int(c) + 256
+}
+
+func verrevcmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := dpkgOrder(a, i), dpkgOrder(b, j)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+// rpmScheme orders versions as rpm does: by epoch, then version, then
+// release with rpmvercmp. The release is only compared when both versions
+// have one.
+type rpmScheme struct{}
+
+func (rpmScheme) Name() string { return "rpm" }
+
+func (rpmScheme) Compare(a, b string) (int, error) {
+	x, err := schemas.ParseRPMVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := schemas.ParseRPMVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if c := compareDigits(x.Epoch, y.Epoch); c != 0 {
+		return c, nil
+	}
+	if c := rpmvercmp(x.Upstream, y.Upstream); c != 0 {
+		return c, nil
+	}
+	if x.Release == "" || y.Release == "" {
+		return 0, nil
+	}
+	return rpmvercmp(x.Release, y.Release), nil
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isLetter(c)
+}
+
+// rpmvercmp compares alternating runs of digits and letters, ignoring other
+// separators. ~ sorts before anything, even the end of the string, and ^
+// sorts after the end of the string but before anything else.
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		if (i < len(a) && a[i] == '~') || (j < len(b) && b[j] == '~') {
+			if i >= len(a) || a[i] != '~' {
+				return 1
+			}
+			if j >= len(b) || b[j] != '~' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if (i < len(a) && a[i] == '^') || (j < len(b) && b[j] == '^') {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if a[i] != '^' {
+				return 1
+			}
+			if b[j] != '^' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		numeric := isDigit(a[i])
+		runEnd := func(s string, k int) int {
+			for k < len(s) && (numeric && isDigit(s[k]) || !numeric && isLetter(s[k])) {
+				k++
+			}
+			return k
+		}
+		iEnd, jEnd := runEnd(a, i), runEnd(b, j)
+
+		// runs of different kinds: numbers are newer than letters
+		if jEnd == j {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		var c int
+		if numeric {
+			c = compareDigits(a[i:iEnd], b[j:jEnd])
+		} else {
+			c = strings.Compare(a[i:iEnd], b[j:jEnd])
+		}
+		if c != 0 {
+			return c
+		}
+		i, j = iEnd, jEnd
+	}
+
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i < len(a):
+		return 1
+	}
+	return -1
+}
+
+// apkScheme orders Alpine package versions:
+// number{.number}[letter]{_suffix[number]}[-rN].
+type apkScheme struct{}
+
+func (apkScheme) Name() string { return "apk" }
+
+func (apkScheme) Compare(a, b string) (int, error) {
+	x, err := parseAPK(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := parseAPK(b)
+	if err != nil {
+		return 0, err
+	}
+	return x.compare(y), nil
+}
+
+// apkSuffixes lists the suffixes in order; the versions without a suffix sort
+// between rc and cvs.
+var apkSuffixes = map[string]int{
+	"alpha": -4, "beta": -3, "pre": -2, "rc": -1,
+	"cvs": 1, "svn": 2, "git": 3, "hg": 4, "p": 5,
+}
+
+type apkSuffix struct {
+	rank   int
+	number string
+}
+
+type apkVersion struct {
+	numbers []string
+	letter  byte
+	// letterNumber follows the letter in versions such as 3.3.3p1
+	letterNumber string
+	suffixes     []apkSuffix
+	revision     string
+}
+
+func parseAPK(version string) (apkVersion, error) {
+	v := strings.TrimSpace(version)
+	parsed := apkVersion{}
+
+	if i := strings.LastIndex(v, "-r"); i >= 0 && allDigits(v[i+2:]) {
+		parsed.revision = v[i+2:]
+		v = v[:i]
+	}
+
+	main, suffixes, _ := strings.Cut(v, "_")
+	if trimmed := strings.TrimRight(main, "0123456789"); trimmed != main && trimmed != "" && isLetter(trimmed[len(trimmed)-1]) {
+		parsed.letterNumber = main[len(trimmed):]
+		main = trimmed
+	}
+	if main != "" && isLetter(main[len(main)-1]) {
+		parsed.letter = main[len(main)-1]
+		main = main[:len(main)-1]
+	}
+	parsed.numbers = strings.Split(main, ".")
+	for _, number := range parsed.numbers {
+		if !allDigits(number) {
+			return apkVersion{}, fmt.Errorf("invalid apk version %q", version)
+		}
+	}
+
+	if suffixes != "" {
+		for _, suffix := range strings.Split(suffixes, "_") {
+			name := strings.TrimRight(suffix, "0123456789")
+			rank, ok := apkSuffixes[name]
+			if !ok {
+				return apkVersion{}, fmt.Errorf("invalid apk version %q: unknown suffix %q", version, suffix)
+			}
+			parsed.suffixes = append(parsed.suffixes, apkSuffix{rank: rank, number: suffix[len(name):]})
+		}
+	}
+	return parsed, nil
+}
+
+func (a apkVersion) compare(b apkVersion) int {
+	for i := 0; i < len(a.numbers) && i < len(b.numbers); i++ {
+		var c int
+		// after the first number, a leading zero makes the number compare
+		// as a decimal fraction
+		if i > 0 && (strings.HasPrefix(a.numbers[i], "0") || strings.HasPrefix(b.numbers[i], "0")) {
+			c = strings.Compare(a.numbers[i], b.numbers[i])
+		} else {
+			c = compareDigits(a.numbers[i], b.numbers[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	if c := sign(len(a.numbers) - len(b.numbers)); c != 0 {
+		return c
+	}
+
+	if c := sign(int(a.letter) - int(b.letter)); c != 0 {
+		return c
+	}
+	if c := compareDigits(a.letterNumber, b.letterNumber); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(a.suffixes) || i < len(b.suffixes); i++ {
+		x, y := apkSuffix{}, apkSuffix{}
+		if i < len(a.suffixes) {
+			x = a.suffixes[i]
+		}
+		if i < len(b.suffixes) {
+			y = b.suffixes[i]
+		}
+		if c := sign(x.rank - y.rank); c != 0 {
+			return c
+		}
+		if c := compareDigits(x.number, y.number); c != 0 {
+			return c
+		}
+	}
+
+	return compareDigits(a.revision, b.revision)
+}
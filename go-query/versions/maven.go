@@ -0,0 +1,214 @@
+package versions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mavenScheme orders versions the way Maven's ComparableVersion does: the
+// version is split into numbers and qualifiers at dots, hyphens and changes
+// between digits and letters, hyphens start a nested list, and known
+// qualifiers sort as alpha < beta < milestone < rc < snapshot < release < sp
+// with any other qualifier after them in string order.
+type mavenScheme struct{}
+
+func (mavenScheme) Name() string { return "maven" }
+
+func (mavenScheme) Compare(a, b string) (int, error) {
+	return parseMaven(a).compare(parseMaven(b)), nil
+}
+
+// mavenItem is a number, a qualifier or a nested list. A nil mavenItem stands
+// for a missing item, which compares like zero or the release qualifier.
+type mavenItem interface {
+	compare(other mavenItem) int
+	isNull() bool
+}
+
+type mavenInt string
+
+type mavenString string
+
+type mavenList []mavenItem
+
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var mavenAliases = map[string]string{"ga": "", "final": "", "release": "", "cr": "rc"}
+
+// mavenReleaseQualifier is the comparable form of the empty qualifier.
+var mavenReleaseQualifier = mavenString("").comparable()
+
+func newMavenString(value string, followedByDigit bool) mavenString {
+	if followedByDigit && len(value) == 1 {
+		switch value {
+		case "a":
+			value = "alpha"
+		case "b":
+			value = "beta"
+		case "m":
+			value = "milestone"
+		}
+	}
+	if alias, ok := mavenAliases[value]; ok {
+		value = alias
+	}
+	return mavenString(value)
+}
+
+// comparable returns the string a qualifier is ordered by.
+func (s mavenString) comparable() string {
+	for i, qualifier := range mavenQualifiers {
+		if string(s) == qualifier {
+			return strconv.Itoa(i)
+		}
+	}
+	return strconv.Itoa(len(mavenQualifiers)) + "-" + string(s)
+}
+
+func (i mavenInt) isNull() bool { return strings.TrimLeft(string(i), "0") == "" }
+
+func (s mavenString) isNull() bool { return s.comparable() == mavenReleaseQualifier }
+
+func (l *mavenList) isNull() bool { return len(*l) == 0 }
+
+func (i mavenInt) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		if i.isNull() {
+			return 0
+		}
+		return 1
+	case mavenInt:
+		return compareDigits(string(i), string(o))
+	}
+	return 1
+}
+
+func (s mavenString) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		return strings.Compare(s.comparable(), mavenReleaseQualifier)
+	case mavenInt:
+		return -1
+	case mavenString:
+		return strings.Compare(s.comparable(), o.comparable())
+	}
+	return -1
+}
+
+func (l *mavenList) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		if len(*l) == 0 {
+			return 0
+		}
+		return (*l)[0].compare(nil)
+	case mavenInt:
+		return -1
+	case mavenString:
+		return 1
+	case *mavenList:
+		for i := 0; i < len(*l) || i < len(*o); i++ {
+			var left, right mavenItem
+			if i < len(*l) {
+				left = (*l)[i]
+			}
+			if i < len(*o) {
+				right = (*o)[i]
+			}
+
+			var c int
+			switch {
+			case left == nil && right == nil:
+				c = 0
+			case left == nil:
+				c = -right.compare(left)
+			default:
+				c = left.compare(right)
+			}
+			if c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// normalize drops the null items at the end of the list, stopping at the
+// last item that is not a list.
+func (l *mavenList) normalize() {
+	for i := len(*l) - 1; i >= 0; i-- {
+		item := (*l)[i]
+		if item.isNull() {
+			*l = append((*l)[:i], (*l)[i+1:]...)
+		} else if _, isList := item.(*mavenList); !isList {
+			break
+		}
+	}
+}
+
+func parseMaven(version string) *mavenList {
+	version = strings.ToLower(strings.TrimSpace(version))
+
+	root := &mavenList{}
+	list := root
+	stack := []*mavenList{root}
+
+	parseItem := func(digits bool, s string) mavenItem {
+		if digits {
+			return mavenInt(s)
+		}
+		return newMavenString(s, false)
+	}
+	startList := func() {
+		next := &mavenList{}
+		*list = append(*list, next)
+		list = next
+		stack = append(stack, next)
+	}
+
+	digits := false
+	start := 0
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		switch {
+		case c == '.':
+			if i == start {
+				*list = append(*list, mavenInt("0"))
+			} else {
+				*list = append(*list, parseItem(digits, version[start:i]))
+			}
+			start = i + 1
+		case c == '-':
+			if i == start {
+				*list = append(*list, mavenInt("0"))
+			} else {
+				*list = append(*list, parseItem(digits, version[start:i]))
+			}
+			start = i + 1
+			startList()
+		case isDigit(c):
+			if !digits && i > start {
+				*list = append(*list, newMavenString(version[start:i], true))
+				start = i
+				startList()
+			}
+			digits = true
+		default:
+			if digits && i > start {
+				*list = append(*list, parseItem(true, version[start:i]))
+				start = i
+				startList()
+			}
+			digits = false
+		}
+	}
+	if len(version) > start {
+		*list = append(*list, parseItem(digits, version[start:]))
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].normalize()
+	}
+	return root
+}
@@ -0,0 +1,181 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pep440Pattern is the version pattern of PEP 440 Appendix B, which accepts
+// the alternative spellings the PEP normalizes.
+var pep440Pattern = regexp.MustCompile(`(?i)^v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?P<pre>[-_.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?))?` +
+	`(?P<dev>[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+type pep440 struct {
+	epoch   string
+	release []string
+	// preLabel is a, b or rc; empty without a pre-release
+	preLabel string
+	pre      string
+	hasPost  bool
+	post     string
+	hasDev   bool
+	dev      string
+	hasLocal bool
+	local    []string
+}
+
+func parsePEP440(version string) (pep440, error) {
+	match := pep440Pattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return pep440{}, fmt.Errorf("invalid PEP 440 version %q", version)
+	}
+	group := func(name string) string {
+		return match[pep440Pattern.SubexpIndex(name)]
+	}
+
+	parsed := pep440{epoch: group("epoch"), release: strings.Split(group("release"), ".")}
+	if parsed.epoch == "" {
+		parsed.epoch = "0"
+	}
+
+	if label := strings.ToLower(group("pre_l")); label != "" {
+		switch label {
+		case "alpha", "a":
+			parsed.preLabel = "a"
+		case "beta", "b":
+			parsed.preLabel = "b"
+		default:
+			parsed.preLabel = "rc"
+		}
+		parsed.pre = group("pre_n")
+	}
+
+	if group("post") != "" {
+		parsed.hasPost = true
+		parsed.post = group("post_n1") + group("post_n2")
+	}
+	if group("dev") != "" {
+		parsed.hasDev = true
+		parsed.dev = group("dev_n")
+	}
+	if local := strings.ToLower(group("local")); local != "" {
+		parsed.hasLocal = true
+		parsed.local = strings.FieldsFunc(local, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	}
+	return parsed, nil
+}
+
+// preRank places the pre-release part of a version relative to others of the
+// same release: a development release of the final version comes before its
+// pre-releases, and the final version after them.
+func (v pep440) preRank() int {
+	switch {
+	case v.preLabel == "" && !v.hasPost && v.hasDev:
+		return -1
+	case v.preLabel == "":
+		return 4
+	case v.preLabel == "a":
+		return 1
+	case v.preLabel == "b":
+		return 2
+	}
+	return 3
+}
+
+func (a pep440) compare(b pep440) int {
+	if c := compareDigits(a.epoch, b.epoch); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(a.release) || i < len(b.release); i++ {
+		x, y := "0", "0"
+		if i < len(a.release) {
+			x = a.release[i]
+		}
+		if i < len(b.release) {
+			y = b.release[i]
+		}
+		if c := compareDigits(x, y); c != 0 {
+			return c
+		}
+	}
+
+	if c := sign(a.preRank() - b.preRank()); c != 0 {
+		return c
+	}
+	if a.preLabel != "" {
+		if c := compareDigits(a.pre, b.pre); c != 0 {
+			return c
+		}
+	}
+
+	// no post-release sorts before any post-release
+	if a.hasPost != b.hasPost {
+		if a.hasPost {
+			return 1
+		}
+		return -1
+	}
+	if c := compareDigits(a.post, b.post); c != 0 {
+		return c
+	}
+
+	// no development release sorts after any development release
+	if a.hasDev != b.hasDev {
+		if a.hasDev {
+			return -1
+		}
+		return 1
+	}
+	if c := compareDigits(a.dev, b.dev); c != 0 {
+		return c
+	}
+
+	if a.hasLocal != b.hasLocal {
+		if a.hasLocal {
+			return 1
+		}
+		return -1
+	}
+	for i := 0; i < len(a.local) && i < len(b.local); i++ {
+		x, y := a.local[i], b.local[i]
+		xNumeric, yNumeric := allDigits(x), allDigits(y)
+		var c int
+		switch {
+		case xNumeric && yNumeric:
+			c = compareDigits(x, y)
+		case xNumeric:
+			c = 1
+		case yNumeric:
+			c = -1
+		default:
+			c = strings.Compare(x, y)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return sign(len(a.local) - len(b.local))
+}
+
+type pep440Scheme struct{}
+
+func (pep440Scheme) Name() string { return "pypi" }
+
+func (pep440Scheme) Compare(a, b string) (int, error) {
+	x, err := parsePEP440(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := parsePEP440(b)
+	if err != nil {
+		return 0, err
+	}
+	return x.compare(y), nil
+}
@@ -0,0 +1,416 @@
+package versions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-query/schemas"
+)
+
+// Constraint is one comparison of a range, such as >=1.2.0.
+type Constraint struct {
+	Op      string
+	Version string
+}
+
+// Range is a set of versions of one ecosystem: a version is in the range if
+// it satisfies every constraint of any one of the alternatives.
+type Range struct {
+	Scheme       Scheme
+	Alternatives [][]Constraint
+}
+
+// ParseRange parses a version range for an ecosystem. It accepts
+//
+//   - vers URIs, such as vers:npm/>=1.0.0|<2.0.0, whose own scheme is used
+//   - Maven intervals, such as [1.0,2.0),[3.0,)
+//   - comparator lists, such as ">= 1.0, < 2.0 || = 3.0", with the operators
+//     =, ==, !=, <, <=, >, >=, the npm and cargo ^ and ~, the PEP 440 ~=, and
+//     trailing .* or .x wildcards
+//
+// An empty range or * matches every version.
+func ParseRange(ecosystem, expr string) (Range, error) {
+	expr = strings.TrimSpace(expr)
+
+	if strings.HasPrefix(expr, "vers:") {
+		return parseVers(expr)
+	}
+
+	scheme, err := ForEcosystem(ecosystem)
+	if err != nil {
+		return Range{}, err
+	}
+	r := Range{Scheme: scheme}
+
+	if strings.HasPrefix(expr, "[") || strings.HasPrefix(expr, "(") {
+		r.Alternatives, err = parseMavenIntervals(expr)
+		if err != nil {
+			return Range{}, err
+		}
+		return r, nil
+	}
+
+	for _, alternative := range strings.Split(expr, "||") {
+		constraints, err := parseComparators(scheme, alternative)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid range %q: %s", expr, err)
+		}
+		r.Alternatives = append(r.Alternatives, constraints)
+	}
+	return r, nil
+}
+
+// Contains reports whether version is in the range.
+func (r Range) Contains(version string) (bool, error) {
+	for _, alternative := range r.Alternatives {
+		matches := true
+		for _, constraint := range alternative {
+			ok, err := constraint.matches(r.Scheme, version)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MatchGuacID reports whether the version of a GuacID is in a range written
+// for its ecosystem, such as the affected range of an advisory.
+func MatchGuacID(gID schemas.GuacID, expr string) (bool, error) {
+	r, err := ParseRange(gID.Ecosystem, expr)
+	if err != nil {
+		return false, err
+	}
+	return r.Contains(GuacIDVersion(gID))
+}
+
+func (c Constraint) matches(scheme Scheme, version string) (bool, error) {
+	if c.Op == "*" {
+		return true, nil
+	}
+	cmp, err := scheme.Compare(version, c.Version)
+	if err != nil {
+		return false, err
+	}
+	switch c.Op {
+	case "=":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return false, fmt.Errorf("unknown operator %q", c.Op)
+}
+
+var rangeOperators = []string{"===", "==", "!=", "<=", ">=", "~=", "=", "<", ">", "^", "~"}
+
+func splitOperator(token string) (string, string) {
+	for _, op := range rangeOperators {
+		if strings.HasPrefix(token, op) {
+			return op, strings.TrimSpace(token[len(op):])
+		}
+	}
+	return "", token
+}
+
+// parseComparators parses the constraints of one alternative, separated by
+// commas or spaces. An operator may be separated from its version by a space,
+// and "a - b" is the npm hyphen range.
+func parseComparators(scheme Scheme, expr string) ([]Constraint, error) {
+	tokens := strings.FieldsFunc(expr, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+
+	constraints := []Constraint{}
+	for i := 0; i < len(tokens); i++ {
+		op, version := splitOperator(tokens[i])
+		if op != "" && version == "" {
+			if i+1 == len(tokens) {
+				return nil, fmt.Errorf("operator %s without a version", op)
+			}
+			i++
+			version = tokens[i]
+		}
+
+		if op == "" && i+2 < len(tokens) && tokens[i+1] == "-" {
+			constraints = append(constraints, Constraint{">=", version}, Constraint{"<=", tokens[i+2]})
+			i += 2
+			continue
+		}
+
+		expanded, err := expandComparator(scheme, op, version)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, expanded...)
+	}
+
+	if len(constraints) == 0 {
+		constraints = append(constraints, Constraint{Op: "*"})
+	}
+	return constraints, nil
+}
+
+func expandComparator(scheme Scheme, op, version string) ([]Constraint, error) {
+	if version == "*" || version == "x" {
+		return []Constraint{{Op: "*"}}, nil
+	}
+
+	if prefix, ok := wildcardPrefix(version); ok && (op == "" || op == "=" || op == "==" || op == "!=") {
+		upper, err := bumpComponent(prefix, len(strings.Split(prefix, "."))-1)
+		if err != nil {
+			return nil, err
+		}
+		if op == "!=" {
+			// one constraint can't express "outside of", so the caller's
+			// alternatives would be needed; reject rather than mismatch
+			return nil, fmt.Errorf("!= with a wildcard is not supported")
+		}
+		return []Constraint{{">=", prefix}, {"<", upper}}, nil
+	}
+
+	switch op {
+	case "", "=", "==", "===":
+		return []Constraint{{"=", version}}, nil
+	case "!=", "<", "<=", ">", ">=":
+		return []Constraint{{op, version}}, nil
+	case "^":
+		upper, err := caretUpperBound(version)
+		if err != nil {
+			return nil, err
+		}
+		return []Constraint{{">=", version}, {"<", upper}}, nil
+	case "~":
+		components := len(strings.Split(releasePart(version), "."))
+		index := 1
+		if components == 1 {
+			index = 0
+		}
+		upper, err := bumpComponent(version, index)
+		if err != nil {
+			return nil, err
+		}
+		return []Constraint{{">=", version}, {"<", upper}}, nil
+	case "~=":
+		components := len(strings.Split(releasePart(version), "."))
+		if components < 2 {
+			return nil, fmt.Errorf("~=%s needs at least two release components", version)
+		}
+		upper, err := bumpComponent(version, components-2)
+		if err != nil {
+			return nil, err
+		}
+		return []Constraint{{">=", version}, {"<", upper}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+func wildcardPrefix(version string) (string, bool) {
+	for _, wildcard := range []string{".*", ".x", ".X"} {
+		if strings.HasSuffix(version, wildcard) {
+			return strings.TrimSuffix(version, wildcard), true
+		}
+	}
+	return "", false
+}
+
+// releasePart returns the leading dotted numbers of a version, without a v
+// prefix.
+func releasePart(version string) string {
+	v := strings.TrimPrefix(version, "v")
+	end := 0
+	for end < len(v) && (isDigit(v[end]) || v[end] == '.') {
+		end++
+	}
+	return strings.TrimSuffix(v[:end], ".")
+}
+
+// bumpComponent returns the version with release component index incremented
+// and everything after it dropped, keeping a v prefix.
+func bumpComponent(version string, index int) (string, error) {
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+	components := strings.Split(releasePart(version), ".")
+	if index >= len(components) || !allDigits(components[index]) {
+		return "", fmt.Errorf("cannot find the upper bound of %s", version)
+	}
+	components = components[:index+1]
+	components[index] = incrementDigits(components[index])
+	return prefix + strings.Join(components, "."), nil
+}
+
+// caretUpperBound allows changes that do not modify the left-most non-zero
+// component.
+func caretUpperBound(version string) (string, error) {
+	components := strings.Split(releasePart(version), ".")
+	for i, component := range components {
+		if strings.TrimLeft(component, "0") != "" || i == len(components)-1 {
+			return bumpComponent(version, i)
+		}
+	}
+	return "", fmt.Errorf("cannot find the upper bound of %s", version)
+}
+
+func incrementDigits(digits string) string {
+	b := []byte(digits)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '9' {
+			b[i]++
+			return string(b)
+		}
+		b[i] = '0'
+	}
+	return "1" + string(b)
+}
+
+// parseMavenIntervals parses a Maven version range, a comma separated list of
+// intervals such as [1.0,2.0) or the exact version [1.0].
+func parseMavenIntervals(expr string) ([][]Constraint, error) {
+	alternatives := [][]Constraint{}
+	remainder := strings.ReplaceAll(expr, " ", "")
+	for remainder != "" {
+		if remainder[0] == ',' {
+			remainder = remainder[1:]
+			continue
+		}
+		open := remainder[0]
+		if open != '[' && open != '(' {
+			return nil, fmt.Errorf("invalid maven range %q", expr)
+		}
+		end := strings.IndexAny(remainder, "])")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid maven range %q: unclosed interval", expr)
+		}
+		closing := remainder[end]
+		interval := remainder[1:end]
+		remainder = remainder[end+1:]
+
+		lower, upper, isInterval := strings.Cut(interval, ",")
+		if !isInterval {
+			if open != '[' || closing != ']' || lower == "" {
+				return nil, fmt.Errorf("invalid maven range %q", expr)
+			}
+			alternatives = append(alternatives, []Constraint{{"=", lower}})
+			continue
+		}
+
+		constraints := []Constraint{}
+		if lower != "" {
+			op := ">"
+			if open == '[' {
+				op = ">="
+			}
+			constraints = append(constraints, Constraint{op, lower})
+		}
+		if upper != "" {
+			op := "<"
+			if closing == ']' {
+				op = "<="
+			}
+			constraints = append(constraints, Constraint{op, upper})
+		}
+		if len(constraints) == 0 {
+			constraints = append(constraints, Constraint{Op: "*"})
+		}
+		alternatives = append(alternatives, constraints)
+	}
+	return alternatives, nil
+}
+
+// parseVers parses a vers URI. Its constraints are sorted by version and read
+// as a sequence of intervals, so vers:npm/>=1.0.0|<2.0.0|>=3.0.0 is the two
+// ranges [1.0.0, 2.0.0) and [3.0.0, ...).
+func parseVers(expr string) (Range, error) {
+	schemeName, constraintList, found := strings.Cut(strings.TrimPrefix(expr, "vers:"), "/")
+	if !found {
+		return Range{}, fmt.Errorf("invalid vers %q", expr)
+	}
+	scheme, err := ForEcosystem(schemeName)
+	if err != nil {
+		return Range{}, err
+	}
+	r := Range{Scheme: scheme}
+
+	constraintList = strings.TrimSpace(constraintList)
+	if constraintList == "*" {
+		r.Alternatives = [][]Constraint{{{Op: "*"}}}
+		return r, nil
+	}
+
+	ranged, excluded := []Constraint{}, []Constraint{}
+	for _, token := range strings.Split(constraintList, "|") {
+		op, version := splitOperator(strings.TrimSpace(token))
+		switch op {
+		case "":
+			op = "="
+		case "=", "!=", "<", "<=", ">", ">=":
+		default:
+			return Range{}, fmt.Errorf("invalid vers %q: unknown operator %s", expr, op)
+		}
+		if version == "" {
+			return Range{}, fmt.Errorf("invalid vers %q: empty version", expr)
+		}
+		constraint := Constraint{op, version}
+
+		switch op {
+		case "=":
+			r.Alternatives = append(r.Alternatives, []Constraint{constraint})
+		case "!=":
+			excluded = append(excluded, constraint)
+		default:
+			ranged = append(ranged, constraint)
+		}
+	}
+
+	var sortErr error
+	sort.SliceStable(ranged, func(i, j int) bool {
+		c, err := scheme.Compare(ranged[i].Version, ranged[j].Version)
+		if err != nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return Range{}, fmt.Errorf("invalid vers %q: %s", expr, sortErr)
+	}
+
+	isLower := func(c Constraint) bool { return c.Op == ">" || c.Op == ">=" }
+	for i := 0; i < len(ranged); i++ {
+		current := ranged[i]
+		switch {
+		case isLower(current) && i+1 < len(ranged) && !isLower(ranged[i+1]):
+			r.Alternatives = append(r.Alternatives, []Constraint{current, ranged[i+1]})
+			i++
+		case isLower(current) && i+1 == len(ranged):
+			r.Alternatives = append(r.Alternatives, []Constraint{current})
+		case !isLower(current) && i == 0:
+			r.Alternatives = append(r.Alternatives, []Constraint{current})
+		default:
+			return Range{}, fmt.Errorf("invalid vers %q: constraints do not form intervals", expr)
+		}
+	}
+
+	// excluded versions apply to every interval
+	if len(excluded) > 0 && len(r.Alternatives) == 0 {
+		r.Alternatives = [][]Constraint{{}}
+	}
+	for i := range r.Alternatives {
+		r.Alternatives[i] = append(r.Alternatives[i], excluded...)
+	}
+	return r, nil
+}
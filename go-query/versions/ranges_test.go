@@ -0,0 +1,118 @@
+package versions
+
+import "testing"
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		name      string
+		ecosystem string
+		expr      string
+		in        []string
+		out       []string
+	}{
+		{
+			// vers spec examples
+			name: "vers interval",
+			expr: "vers:npm/>=1.0.0|<2.0.0",
+			in:   []string{"1.0.0", "1.5.0"},
+			out:  []string{"0.9.0", "2.0.0"},
+		},
+		{
+			name: "vers versions and interval",
+			expr: "vers:npm/1.2.3|>=2.0.0|<5.0.0",
+			in:   []string{"1.2.3", "2.0.0", "4.9.9"},
+			out:  []string{"1.2.4", "5.0.0"},
+		},
+		{
+			name: "vers exclusion",
+			expr: "vers:deb/>=1.0.0|<1.1.0|!=1.0.5",
+			in:   []string{"1.0.0", "1.0.4"},
+			out:  []string{"1.0.5", "1.1.0"},
+		},
+		{
+			name: "vers pypi list",
+			expr: "vers:pypi/0.0.0|0.0.1|1.0|2.0pre1",
+			in:   []string{"0.0.1", "1.0.0", "2.0rc1"},
+			out:  []string{"0.0.2", "2.0"},
+		},
+		{
+			name: "vers star",
+			expr: "vers:maven/*",
+			in:   []string{"1.0", "2.0-SNAPSHOT"},
+		},
+		{
+			name:      "maven intervals",
+			ecosystem: "maven",
+			expr:      "[1.0,2.0),[3.0,)",
+			in:        []string{"1.0", "1.5", "3.0", "10.0"},
+			out:       []string{"0.9", "2.0", "2.5"},
+		},
+		{
+			name:      "npm caret",
+			ecosystem: "npm",
+			expr:      "^1.2.3",
+			in:        []string{"1.2.3", "1.9.0"},
+			out:       []string{"1.2.2", "2.0.0"},
+		},
+		{
+			name:      "npm tilde",
+			ecosystem: "npm",
+			expr:      "~1.2.3",
+			in:        []string{"1.2.3", "1.2.9"},
+			out:       []string{"1.3.0"},
+		},
+		{
+			// PEP 440 compatible release
+			name:      "pep 440 compatible release",
+			ecosystem: "pypi",
+			expr:      "~=2.2",
+			in:        []string{"2.2", "2.9"},
+			out:       []string{"2.1", "3.0"},
+		},
+		{
+			name:      "alternatives and wildcard",
+			ecosystem: "pypi",
+			expr:      ">= 1.0, < 2.0 || == 3.*",
+			in:        []string{"1.0", "3.4"},
+			out:       []string{"2.0", "4.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRange(tt.ecosystem, tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, version := range tt.in {
+				if ok, err := r.Contains(version); err != nil || !ok {
+					t.Errorf("Contains(%q) = %t, %v; want true", version, ok, err)
+				}
+			}
+			for _, version := range tt.out {
+				if ok, err := r.Contains(version); err != nil || ok {
+					t.Errorf("Contains(%q) = %t, %v; want false", version, ok, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		expr      string
+	}{
+		{"", "vers:npm"},
+		{"", "vers:npm/~1.0.0"},
+		{"", "vers:npm/>="},
+		{"", "vers:hackage/1.0"},
+		{"maven", "[1.0,2.0"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseRange(tt.ecosystem, tt.expr); err == nil {
+			t.Errorf("ParseRange(%q, %q) succeeded; want an error", tt.ecosystem, tt.expr)
+		}
+	}
+}
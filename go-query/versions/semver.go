@@ -0,0 +1,121 @@
+package versions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// semver is a Semantic Versioning 2.0.0 version. Build metadata does not take
+// part in ordering and is dropped.
+type semver struct {
+	major, minor, patch string
+	prerelease          []string
+}
+
+// parseSemver accepts the loose forms found in the wild: a leading v or =,
+// and a missing minor or patch number, which count as zero.
+func parseSemver(version string) (semver, error) {
+	v := strings.TrimSpace(version)
+	v = strings.TrimPrefix(v, "=")
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+
+	parsed := semver{}
+	if i := strings.Index(v, "-"); i >= 0 {
+		parsed.prerelease = strings.Split(v[i+1:], ".")
+		for _, identifier := range parsed.prerelease {
+			if identifier == "" {
+				return semver{}, fmt.Errorf("invalid semver %q: empty pre-release identifier", version)
+			}
+		}
+		v = v[:i]
+	}
+
+	numbers := strings.Split(v, ".")
+	if len(numbers) > 3 {
+		return semver{}, fmt.Errorf("invalid semver %q: too many components", version)
+	}
+	for _, number := range numbers {
+		if !allDigits(number) {
+			return semver{}, fmt.Errorf("invalid semver %q", version)
+		}
+	}
+	for len(numbers) < 3 {
+		numbers = append(numbers, "0")
+	}
+	parsed.major, parsed.minor, parsed.patch = numbers[0], numbers[1], numbers[2]
+	return parsed, nil
+}
+
+func (a semver) compare(b semver) int {
+	if c := compareDigits(a.major, b.major); c != 0 {
+		return c
+	}
+	if c := compareDigits(a.minor, b.minor); c != 0 {
+		return c
+	}
+	if c := compareDigits(a.patch, b.patch); c != 0 {
+		return c
+	}
+
+	// a version without a pre-release is newer than any of its pre-releases
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		x, y := a.prerelease[i], b.prerelease[i]
+		xNumeric, yNumeric := allDigits(x), allDigits(y)
+		var c int
+		switch {
+		case xNumeric && yNumeric:
+			c = compareDigits(x, y)
+		case xNumeric:
+			c = -1
+		case yNumeric:
+			c = 1
+		default:
+			c = strings.Compare(x, y)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return sign(len(a.prerelease) - len(b.prerelease))
+}
+
+type semverScheme struct{}
+
+func (semverScheme) Name() string { return "semver" }
+
+func (semverScheme) Compare(a, b string) (int, error) {
+	x, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	return x.compare(y), nil
+}
+
+// goScheme orders Go module versions. They are semver with a v prefix, and
+// pseudo-versions such as v0.0.0-20210908233432-aa78b53d3365 already sort by
+// their timestamp as pre-releases. The go toolchain's own go1.21.0 form is
+// accepted too.
+type goScheme struct{}
+
+func (goScheme) Name() string { return "golang" }
+
+func (goScheme) Compare(a, b string) (int, error) {
+	return semverScheme{}.Compare(strings.TrimPrefix(a, "go"), strings.TrimPrefix(b, "go"))
+}
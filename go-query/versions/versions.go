@@ -0,0 +1,150 @@
+// Package versions compares and matches package versions using the rules of
+// the ecosystem they come from, as named by GuacID.Ecosystem.
+package versions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-query/schemas"
+)
+
+var ErrUnknownEcosystem = errors.New("no version scheme for ecosystem")
+
+// Scheme orders the versions of one ecosystem. Compare returns -1, 0 or 1
+// like strings.Compare, or an error if either version is not valid in the
+// scheme.
+type Scheme interface {
+	Name() string
+	Compare(a, b string) (int, error)
+}
+
+var schemes = map[string]Scheme{
+	"semver": semverScheme{},
+	"npm":    semverScheme{},
+	"cargo":  semverScheme{},
+	"golang": goScheme{},
+	"pypi":   pep440Scheme{},
+	"maven":  mavenScheme{},
+	"deb":    debianScheme{},
+	"rpm":    rpmScheme{},
+	"apk":    apkScheme{},
+	"alpine": apkScheme{},
+}
+
+// ForEcosystem returns the scheme for a purl type or vers scheme.
+func ForEcosystem(ecosystem string) (Scheme, error) {
+	scheme, ok := schemes[strings.ToLower(ecosystem)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownEcosystem, ecosystem)
+	}
+	return scheme, nil
+}
+
+func Compare(ecosystem, a, b string) (int, error) {
+	scheme, err := ForEcosystem(ecosystem)
+	if err != nil {
+		return 0, err
+	}
+	return scheme.Compare(a, b)
+}
+
+// Sort orders versions from oldest to newest. Versions the scheme cannot
+// parse are kept after the others, in string order.
+func Sort(ecosystem string, versions []string) error {
+	scheme, err := ForEcosystem(ecosystem)
+	if err != nil {
+		return err
+	}
+
+	valid := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		_, err := scheme.Compare(version, version)
+		valid[version] = err == nil
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		if valid[a] != valid[b] {
+			return valid[a]
+		}
+		if !valid[a] {
+			return a < b
+		}
+		c, _ := scheme.Compare(a, b)
+		return c < 0
+	})
+	return nil
+}
+
+// GuacIDVersion puts back together the version a GuacID was made from, as
+// its ecosystem's scheme expects it. Debian and RPM versions are stored split
+// into Epoch, Version and PkgRel.
+func GuacIDVersion(gID schemas.GuacID) string {
+	switch gID.Ecosystem {
+	case "deb", "rpm":
+		version := gID.Version
+		if gID.Epoch != "" {
+			version = gID.Epoch + ":" + version
+		}
+		if gID.PkgRel != "" {
+			version += "-" + gID.PkgRel
+		}
+		return version
+	}
+	return gID.Version
+}
+
+// CompareGuacIDs compares the versions of two GuacIDs of the same ecosystem.
+func CompareGuacIDs(a, b schemas.GuacID) (int, error) {
+	if a.Ecosystem != b.Ecosystem {
+		return 0, fmt.Errorf("cannot compare versions of %s and %s", a.Ecosystem, b.Ecosystem)
+	}
+	return Compare(a.Ecosystem, GuacIDVersion(a), GuacIDVersion(b))
+}
+
+// compareDigits compares two strings of decimal digits by value, without
+// limiting how large they may be.
+func compareDigits(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package versions
+
+import "testing"
+
+// TestCompareOrdering checks that each list, taken from the examples of the
+// ecosystem's own specification, is sorted from oldest to newest.
+func TestCompareOrdering(t *testing.T) {
+	tests := []struct {
+		name      string
+		ecosystem string
+		ordered   []string
+	}{
+		{
+			// semver 2.0.0, section 11
+			name:      "semver precedence",
+			ecosystem: "semver",
+			ordered:   []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "2.0.0", "2.1.0", "2.1.1"},
+		},
+		{
+			name:      "go pseudo-versions",
+			ecosystem: "golang",
+			ordered:   []string{"v0.0.0-20191109021931-daa7c04131f5", "v0.1.0", "v1.2.3-pre", "v1.2.3", "v2.0.0+incompatible"},
+		},
+		{
+			// PEP 440, summary of permitted suffixes and relative ordering
+			name:      "pep 440 suffixes",
+			ecosystem: "pypi",
+			ordered:   []string{"1.0.dev456", "1.0a1", "1.0a2.dev456", "1.0a12.dev456", "1.0a12", "1.0b1.dev456", "1.0b2", "1.0b2.post345.dev456", "1.0b2.post345", "1.0rc1.dev456", "1.0rc1", "1.0", "1.0+abc.5", "1.0+abc.7", "1.0+5", "1.0.post456.dev34", "1.0.post456", "1.1.dev1"},
+		},
+		{
+			// Maven ComparableVersion: known qualifiers come in a fixed order,
+			// with the release between snapshot and sp
+			name:      "maven qualifiers",
+			ecosystem: "maven",
+			ordered:   []string{"1-alpha", "1-beta", "1-milestone", "1-rc", "1-snapshot", "1", "1-sp", "1-abc", "1.1"},
+		},
+		{
+			name:      "maven numbers",
+			ecosystem: "maven",
+			ordered:   []string{"1", "1-1", "1.1", "1.2", "1.10", "2.0-rc1", "2.0", "2.0.1"},
+		},
+		{
+			// Debian policy 5.6.12: ~ sorts before everything, even the end
+			// of the version
+			name:      "dpkg tilde",
+			ecosystem: "deb",
+			ordered:   []string{"1.0~~", "1.0~~a", "1.0~", "1.0", "1.0a"},
+		},
+		{
+			name:      "dpkg epoch and revision",
+			ecosystem: "deb",
+			ordered:   []string{"1.0~rc1-1", "1.0-1", "1.0-2", "1.0-10", "1.0.1-1", "1:0.9-1", "2:0.1"},
+		},
+		{
+			// rpmvercmp: ~ sorts before the end of the version and ^ after it,
+			// but before any further segment
+			name:      "rpmvercmp tilde and caret",
+			ecosystem: "rpm",
+			ordered:   []string{"1.0~rc1", "1.0~rc2", "1.0", "1.0^git1", "1.0^git2", "1.01"},
+		},
+		{
+			name:      "rpmvercmp segments",
+			ecosystem: "rpm",
+			ordered:   []string{"5.5p1", "5.5p2", "5.5p10", "10xyz", "10.1xyz", "2:1.0"},
+		},
+		{
+			name:      "apk suffixes",
+			ecosystem: "apk",
+			ordered:   []string{"1.2.3_alpha", "1.2.3_beta2", "1.2.3_pre", "1.2.3_rc1", "1.2.3", "1.2.3-r1", "1.2.3-r2", "1.2.3_p1", "1.2.4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i+1 < len(tt.ordered); i++ {
+				a, b := tt.ordered[i], tt.ordered[i+1]
+				if got, err := Compare(tt.ecosystem, a, b); err != nil || got != -1 {
+					t.Errorf("Compare(%q, %q) = %d, %v; want -1", a, b, got, err)
+				}
+				if got, err := Compare(tt.ecosystem, b, a); err != nil || got != 1 {
+					t.Errorf("Compare(%q, %q) = %d, %v; want 1", b, a, got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		a, b      string
+	}{
+		{"semver", "1.0.0+build.1", "1.0.0+build.2"},
+		{"semver", "v1.2", "1.2.0"},
+		{"pypi", "1.0", "1.0.0"},
+		{"pypi", "1.0a1", "1.0.alpha.1"},
+		{"pypi", "1.0.post1", "1.0-1"},
+		{"maven", "1", "1.0.0"},
+		{"maven", "1-ga", "1"},
+		{"maven", "1-final", "1"},
+		{"maven", "1-cr", "1-rc"},
+		{"maven", "1-a1", "1-alpha-1"},
+		{"maven", "1-m1", "1-milestone-1"},
+		{"maven", "1.0-RC1", "1.0-rc-1"},
+		{"deb", "0:1.0-1", "1.0-1"},
+		{"deb", "1.0-01", "1.0-1"},
+		{"rpm", "1.0", "1.0"},
+		{"rpm", "1.001", "1.1"},
+		{"apk", "1.2.3-r0", "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		if got, err := Compare(tt.ecosystem, tt.a, tt.b); err != nil || got != 0 {
+			t.Errorf("Compare(%s, %q, %q) = %d, %v; want 0", tt.ecosystem, tt.a, tt.b, got, err)
+		}
+	}
+}
+
+func TestCompareInvalid(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		version   string
+	}{
+		{"semver", "1.x.0"},
+		{"semver", "1.2.3.4"},
+		{"semver", "1.0.0-rc..1"},
+		{"pypi", "1.0-foo"},
+		{"deb", "a1.0"},
+		{"apk", "1.2.3_bogus"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Compare(tt.ecosystem, tt.version, tt.version); err == nil {
+			t.Errorf("Compare(%s, %q) succeeded; want an error", tt.ecosystem, tt.version)
+		}
+	}
+
+	if _, err := Compare("hackage", "1.0", "1.0"); err == nil {
+		t.Errorf("Compare(hackage) succeeded; want ErrUnknownEcosystem")
+	}
+}
+
+func TestSort(t *testing.T) {
+	versions := []string{"1.10.0", "not-a-version", "1.2.0", "1.2.0-rc.1"}
+	if err := Sort("npm", versions); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1.2.0-rc.1", "1.2.0", "1.10.0", "not-a-version"}
+	for i := range want {
+		if versions[i] != want[i] {
+			t.Fatalf("Sort = %v; want %v", versions, want)
+		}
+	}
+}